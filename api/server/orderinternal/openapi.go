@@ -0,0 +1,8 @@
+package orderinternal
+
+import _ "embed"
+
+// OpenAPISpec is generated by protoc-gen-openapiv2 from google.api.http annotations
+//
+//go:embed orderinternal.swagger.json
+var OpenAPISpec []byte
@@ -19,6 +19,16 @@ type Service struct {
 	GRPCAddress string `envconfig:"grpc_address" default:":8081"`
 	HTTPAddress string `envconfig:"http_address" default:":8082"`
 
+	HealthCheckTimeout  time.Duration `envconfig:"health_check_timeout" default:"1s"`
+	HealthCheckCacheTTL time.Duration `envconfig:"health_check_cache_ttl" default:"2s"`
+	// HealthCheckInterval is how often gRPC health service status is updated from dependency checks
+	HealthCheckInterval time.Duration `envconfig:"health_check_interval" default:"5s"`
+
+	GRPCReflection bool `envconfig:"grpc_reflection" default:"false"`
+
+	// WatchHeartbeatInterval is how often idle WatchOrder stream gets heartbeat
+	WatchHeartbeatInterval time.Duration `envconfig:"watch_heartbeat_interval" default:"15s"`
+
 	TemporalAddress string `envconfig:"temporal_address" default:"temporal:7233"`
 
 	ProductServiceAddress      string `envconfig:"product_service_address" default:"product-service:8081"`
@@ -26,6 +36,118 @@ type Service struct {
 	NotificationServiceAddress string `envconfig:"notification_service_address" default:"notification-service:8081"`
 }
 
+type Tracing struct {
+	// Exporter is one of none, stdout or otlp
+	Exporter     string  `envconfig:"exporter" default:"none"`
+	OTLPEndpoint string  `envconfig:"otlp_endpoint" default:"otel-collector:4317"`
+	SampleRatio  float64 `envconfig:"sample_ratio" default:"1"`
+}
+
+type Auth struct {
+	Enabled bool `envconfig:"enabled" default:"false"`
+	// JWKSFile is path to JSON Web Key Set, JWKS with inline key set is used if it is empty
+	JWKSFile string        `envconfig:"jwks_file"`
+	JWKS     string        `envconfig:"jwks"`
+	Issuer   string        `envconfig:"issuer"`
+	Audience string        `envconfig:"audience"`
+	Leeway   time.Duration `envconfig:"leeway" default:"1m"`
+}
+
+type GRPCClient struct {
+	// Timeout is deadline of whole call, all retry attempts share it
+	Timeout        time.Duration `envconfig:"timeout" default:"5s"`
+	MaxAttempts    int           `envconfig:"max_attempts" default:"3"`
+	InitialBackoff time.Duration `envconfig:"initial_backoff" default:"100ms"`
+	MaxBackoff     time.Duration `envconfig:"max_backoff" default:"2s"`
+	// BreakerMaxFailures is count of consecutive failures opening circuit breaker
+	BreakerMaxFailures uint32        `envconfig:"breaker_max_failures" default:"5"`
+	BreakerOpenTimeout time.Duration `envconfig:"breaker_open_timeout" default:"30s"`
+}
+
+// Activity options are applied to workflows started after change, running workflows keep their options
+type Activity struct {
+	StartToCloseTimeout time.Duration `envconfig:"start_to_close_timeout" default:"1m"`
+	// ScheduleToCloseTimeout limits all attempts together, zero means unlimited
+	ScheduleToCloseTimeout time.Duration `envconfig:"schedule_to_close_timeout"`
+	InitialInterval        time.Duration `envconfig:"initial_interval" default:"1s"`
+	BackoffCoefficient     float64       `envconfig:"backoff_coefficient" default:"2"`
+	MaximumInterval        time.Duration `envconfig:"maximum_interval" default:"1m"`
+	// MaximumAttempts includes the first attempt, zero means unlimited. Unset falls back to default of activity,
+	// payment without idempotency key is attempted once and other activities 5 times
+	MaximumAttempts *int32 `envconfig:"maximum_attempts"`
+}
+
+type Activities struct {
+	CreateOrder      Activity `envconfig:"create_order"`
+	GetProductPrice  Activity `envconfig:"get_product_price"`
+	AddItem          Activity `envconfig:"add_item"`
+	ProcessPayment   Activity `envconfig:"process_payment"`
+	MarkOrderPaid    Activity `envconfig:"mark_order_paid"`
+	SendNotification Activity `envconfig:"send_notification"`
+
+	FindExpiredOrders Activity `envconfig:"find_expired_orders"`
+	ExpireOrder       Activity `envconfig:"expire_order"`
+}
+
+// OrderExpiry cancels open and pending orders after TTL, schedule is shared by all replicas.
+// It is disabled by default since orders created by CreateOrderWorkflow started before paid status was set
+// and orders created asynchronously stay open after payment
+type OrderExpiry struct {
+	Enabled   bool          `envconfig:"enabled" default:"false"`
+	TTL       time.Duration `envconfig:"ttl" default:"24h"`
+	Interval  time.Duration `envconfig:"interval" default:"10m"`
+	BatchSize int           `envconfig:"batch_size" default:"100"`
+}
+
+// RateLimit limits authenticated users, requests without user are limited only by address or as unknown callers
+type RateLimit struct {
+	Enabled bool    `envconfig:"enabled" default:"true"`
+	RPS     float64 `envconfig:"rps" default:"10"`
+	Burst   int     `envconfig:"burst" default:"20"`
+	// CreateOrder limits are applied to both sync and async order creation
+	CreateOrderRPS   float64 `envconfig:"create_order_rps" default:"1"`
+	CreateOrderBurst int     `envconfig:"create_order_burst" default:"5"`
+	// ByAddress limits requests without user by client address, all services behind one address share limit
+	ByAddress bool `envconfig:"by_address" default:"false"`
+	// TrustedProxies are CIDRs of proxies whose last x-forwarded-for address is client address, REST gateway calls over loopback
+	TrustedProxies []string `envconfig:"trusted_proxies" default:"127.0.0.0/8,::1/128"`
+	// LimitUnknownCallers makes requests without known caller share one limit, they aren't limited otherwise
+	LimitUnknownCallers bool `envconfig:"limit_unknown_callers" default:"false"`
+}
+
+// Quotas are disabled with zero values
+type Quotas struct {
+	MaxOpenOrders    int `envconfig:"max_open_orders" default:"20"`
+	MaxOrdersPerHour int `envconfig:"max_orders_per_hour" default:"60"`
+}
+
+type ProductCache struct {
+	Enabled bool          `envconfig:"enabled" default:"true"`
+	TTL     time.Duration `envconfig:"ttl" default:"5m"`
+	MaxSize int           `envconfig:"max_size" default:"10000"`
+	// Warmup loads all product prices on start, failed warm-up doesn't stop service
+	Warmup        bool          `envconfig:"warmup" default:"true"`
+	WarmupTimeout time.Duration `envconfig:"warmup_timeout" default:"10s"`
+}
+
+type ServerTLS struct {
+	Enabled  bool   `envconfig:"enabled" default:"false"`
+	CertFile string `envconfig:"cert_file"`
+	KeyFile  string `envconfig:"key_file"`
+	// ClientCAFile enables mTLS, clients with certificates are verified against it
+	ClientCAFile      string `envconfig:"client_ca_file"`
+	RequireClientCert bool   `envconfig:"require_client_cert" default:"false"`
+}
+
+type ClientTLS struct {
+	Enabled bool `envconfig:"enabled" default:"false"`
+	// CAFile is CA bundle to verify server, system roots are used if it is empty
+	CAFile     string `envconfig:"ca_file"`
+	CertFile   string `envconfig:"cert_file"`
+	KeyFile    string `envconfig:"key_file"`
+	ServerName string `envconfig:"server_name"`
+}
+
 type AMQP struct {
 	User           string        `envconfig:"user" required:"true"`
 	Password       string        `envconfig:"password" required:"true"`
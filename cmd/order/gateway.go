@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"order/api/server/orderinternal"
+)
+
+// registerGateway mounts REST gateway calling own gRPC server, so REST requests pass the same interceptors
+func registerGateway(
+	ctx context.Context,
+	router *mux.Router,
+	grpcAddress string,
+	creds credentials.TransportCredentials,
+) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(
+		localEndpoint(grpcAddress),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gatewayMux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			if strings.EqualFold(key, "X-Actor") {
+				return "x-actor", true
+			}
+			return runtime.DefaultHeaderMatcher(key)
+		}),
+	)
+	err = orderinternal.RegisterOrderInternalServiceHandler(ctx, gatewayMux, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	router.PathPrefix("/v1/").Handler(gatewayMux)
+	router.HandleFunc("/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(orderinternal.OpenAPISpec)
+	})
+	return conn, nil
+}
+
+// localEndpoint turns listen address like :8081 into address to dial
+func localEndpoint(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
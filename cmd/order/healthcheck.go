@@ -1,13 +1,31 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
+
+	"order/pkg/infrastructure/health"
+	"order/pkg/infrastructure/migrations/database"
 )
 
-func registerHealthcheck(router *mux.Router) {
+type healthResponse struct {
+	Status        string `json:"status"`
+	SchemaVersion int64  `json:"schemaVersion"`
+}
+
+// registerHealthcheck registers /readyz checking dependencies and /livez checking process itself,
+// /healthz is kept for existing probes
+func registerHealthcheck(router *mux.Router, schemaVersion database.SchemaVersion, readiness, liveness *health.Checker) {
+	router.Handle("/readyz", readiness.Handler())
+	router.Handle("/livez", liveness.Handler())
 	router.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(healthResponse{
+			Status:        "ok",
+			SchemaVersion: schemaVersion.Current,
+		})
 	})
 }
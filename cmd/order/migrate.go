@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
 	libio "gitea.xscloud.ru/xscloud/golib/pkg/common/io"
@@ -12,23 +17,87 @@ import (
 )
 
 type migrateConfig struct {
-	Database Database `envconfig:"database" required:"true"`
+	Database    Database      `envconfig:"database" required:"true"`
+	LockTimeout time.Duration `envconfig:"migrate_lock_timeout" default:"1m"`
 }
 
+const dryRunFlagName = "dry-run"
+
 func migrate(logger logging.Logger) *cli.Command {
+	dryRunFlag := &cli.BoolFlag{
+		Name:  dryRunFlagName,
+		Usage: "print SQL instead of executing it",
+	}
 	return &cli.Command{
 		Name: "migrate",
 		Subcommands: cli.Commands{
 			&cli.Command{
 				Name:   "database",
+				Usage:  "apply pending database migrations",
+				Flags:  []cli.Flag{dryRunFlag},
 				Action: migrateImpl(logger),
+				Subcommands: cli.Commands{
+					&cli.Command{
+						Name:   "status",
+						Usage:  "list applied and pending migrations",
+						Action: migrateStatusImpl(logger),
+					},
+					&cli.Command{
+						Name:  "down",
+						Usage: "revert last applied migration",
+						Flags: []cli.Flag{dryRunFlag},
+						Action: withDatabaseMigrator(logger, func(_ *cli.Context, migrator database.Migrator) error {
+							return migrator.Down()
+						}),
+					},
+					&cli.Command{
+						Name:      "goto",
+						Usage:     "apply or revert migrations up to version, 0 reverts all migrations",
+						ArgsUsage: "<version>",
+						Flags:     []cli.Flag{dryRunFlag},
+						Action: withDatabaseMigrator(logger, func(c *cli.Context, migrator database.Migrator) error {
+							version, err := strconv.ParseInt(c.Args().First(), 10, 64)
+							if err != nil {
+								return fmt.Errorf("invalid version %q: %w", c.Args().First(), err)
+							}
+							return migrator.Goto(version)
+						}),
+					},
+				},
 			},
+			migrateData(logger),
 		},
 	}
 }
 
 func migrateImpl(logger logging.Logger) func(c *cli.Context) error {
-	return func(c *cli.Context) error {
+	return withDatabaseMigrator(logger, func(_ *cli.Context, migrator database.Migrator) error {
+		return migrator.Migrate()
+	})
+}
+
+func migrateStatusImpl(logger logging.Logger) func(c *cli.Context) error {
+	return withDatabaseMigrator(logger, func(c *cli.Context, migrator database.Migrator) error {
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED AT\tDESCRIPTION")
+		for _, status := range statuses {
+			state, appliedAt := "pending", "-"
+			if status.Applied {
+				state, appliedAt = "applied", status.AppliedAt.Format(time.DateTime)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, state, appliedAt, status.Description)
+		}
+		return w.Flush()
+	})
+}
+
+func withDatabaseMigrator(logger logging.Logger, action func(c *cli.Context, migrator database.Migrator) error) func(c *cli.Context) error {
+	return func(c *cli.Context) (err error) {
 		cnf, err := parseEnvs[migrateConfig]()
 		if err != nil {
 			return err
@@ -46,17 +115,29 @@ func migrateImpl(logger logging.Logger) func(c *cli.Context) error {
 		closer.AddCloser(connector)
 		connPool := mysql.NewConnectionPool(connector.TransactionalClient())
 
-		databaseMigrator, closeDatabaseMigrator, err := database.NewDatabaseMigrator(c.Context, connPool, logger)
-		if err != nil {
-			return err
+		migratorConfig := database.Config{
+			LockTimeout: cnf.LockTimeout,
+		}
+		if c.Bool(dryRunFlagName) {
+			migratorConfig.DryRunOutput = c.App.Writer
 		}
-		closer.AddCloser(libio.CloserFunc(closeDatabaseMigrator))
 
-		err = databaseMigrator.Migrate()
+		databaseMigrator, closeDatabaseMigrator, err := database.NewDatabaseMigrator(c.Context, connPool, logger, migratorConfig)
 		if err != nil {
 			return err
 		}
+		closer.AddCloser(libio.CloserFunc(closeDatabaseMigrator))
+
+		return action(c, databaseMigrator)
+	}
+}
 
-		return nil
+// checkSchemaVersion refuses to serve with database schema older than binary expects
+func checkSchemaVersion(ctx context.Context, pool mysql.ConnectionPool, logger logging.Logger) (database.SchemaVersion, error) {
+	databaseMigrator, closeDatabaseMigrator, err := database.NewDatabaseMigrator(ctx, pool, logger, database.Config{})
+	if err != nil {
+		return database.SchemaVersion{}, err
 	}
+	version, err := databaseMigrator.SchemaVersion()
+	return version, errors.Join(err, closeDatabaseMigrator())
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	libio "gitea.xscloud.ru/xscloud/golib/pkg/common/io"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/urfave/cli/v2"
+
+	"order/pkg/infrastructure/migrations/data"
+)
+
+const (
+	batchSizeFlagName     = "batch-size"
+	batchIntervalFlagName = "batch-interval"
+)
+
+func migrateData(logger logging.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "data",
+		Usage: "manage batched background data migrations",
+		Subcommands: cli.Commands{
+			&cli.Command{
+				Name:      "run",
+				Usage:     "run or resume data migrations, all not paused migrations if names are not given",
+				ArgsUsage: "[name...]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  batchSizeFlagName,
+						Usage: "max rows changed in single transaction",
+						Value: 1000,
+					},
+					&cli.DurationFlag{
+						Name:  batchIntervalFlagName,
+						Usage: "pause between batches",
+						Value: 100 * time.Millisecond,
+					},
+				},
+				Action: withDataMigrator(logger, func(c *cli.Context, migrator data.Migrator) error {
+					return migrator.Run(c.Args().Slice()...)
+				}),
+			},
+			&cli.Command{
+				Name:  "status",
+				Usage: "list data migrations with progress",
+				Action: withDataMigrator(logger, func(c *cli.Context, migrator data.Migrator) error {
+					statuses, err := migrator.Status()
+					if err != nil {
+						return err
+					}
+
+					w := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+					_, _ = fmt.Fprintln(w, "NAME\tSTATE\tPROCESSED ROWS\tUPDATED AT\tDESCRIPTION")
+					for _, status := range statuses {
+						updatedAt := "-"
+						if !status.UpdatedAt.IsZero() {
+							updatedAt = status.UpdatedAt.Format(time.DateTime)
+						}
+						_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", status.Name, status.State, status.ProcessedRows, updatedAt, status.Description)
+					}
+					return w.Flush()
+				}),
+			},
+			&cli.Command{
+				Name:      "pause",
+				Usage:     "stop running data migration after current batch",
+				ArgsUsage: "<name>",
+				Action: withDataMigrator(logger, func(c *cli.Context, migrator data.Migrator) error {
+					if c.Args().Len() != 1 {
+						return errors.New("data migration name is required")
+					}
+					return migrator.Pause(c.Args().First())
+				}),
+			},
+		},
+	}
+}
+
+func withDataMigrator(logger logging.Logger, action func(c *cli.Context, migrator data.Migrator) error) func(c *cli.Context) error {
+	return func(c *cli.Context) (err error) {
+		cnf, err := parseEnvs[migrateConfig]()
+		if err != nil {
+			return err
+		}
+
+		closer := libio.NewMultiCloser()
+		defer func() {
+			err = errors.Join(err, closer.Close())
+		}()
+
+		connector, err := newDatabaseConnector(cnf.Database)
+		if err != nil {
+			return err
+		}
+		closer.AddCloser(connector)
+		connPool := mysql.NewConnectionPool(connector.TransactionalClient())
+
+		dataMigrator, closeDataMigrator, err := data.NewDataMigrator(c.Context, connPool, logger, data.Config{
+			BatchSize:     c.Int(batchSizeFlagName),
+			BatchInterval: c.Duration(batchIntervalFlagName),
+		})
+		if err != nil {
+			return err
+		}
+		closer.AddCloser(libio.CloserFunc(closeDataMigrator))
+
+		return action(c, dataMigrator)
+	}
+}
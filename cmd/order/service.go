@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
 	libio "gitea.xscloud.ru/xscloud/golib/pkg/common/io"
@@ -12,19 +14,32 @@ import (
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 	"github.com/gorilla/mux"
 	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	temporalclient "go.temporal.io/sdk/client"
+	temporalotel "go.temporal.io/sdk/contrib/opentelemetry"
+	"go.temporal.io/sdk/interceptor"
 	"go.temporal.io/sdk/worker"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	"order/api/server/orderinternal"
 	appservice "order/pkg/application/service"
 	infraamqp "order/pkg/infrastructure/amqp"
+	"order/pkg/infrastructure/auth"
+	"order/pkg/infrastructure/cache"
 	"order/pkg/infrastructure/client"
+	"order/pkg/infrastructure/health"
+	"order/pkg/infrastructure/metrics"
 	inframysql "order/pkg/infrastructure/mysql"
 	"order/pkg/infrastructure/mysql/query"
 	infratemporal "order/pkg/infrastructure/temporal"
+	"order/pkg/infrastructure/tlscredentials"
+	"order/pkg/infrastructure/tracing"
 	"order/pkg/infrastructure/transport"
 	"order/pkg/infrastructure/transport/middlewares"
 )
@@ -33,18 +48,74 @@ type serviceConfig struct {
 	Service  Service  `envconfig:"service"`
 	Database Database `envconfig:"database" required:"true"`
 	AMQP     AMQP     `envconfig:"amqp" required:"true"`
+	Tracing  Tracing  `envconfig:"tracing"`
+	Auth     Auth     `envconfig:"auth"`
+
+	ServerTLS       ServerTLS `envconfig:"server_tls"`
+	ProductTLS      ClientTLS `envconfig:"product_tls"`
+	PaymentTLS      ClientTLS `envconfig:"payment_tls"`
+	NotificationTLS ClientTLS `envconfig:"notification_tls"`
+	// GatewayTLS is used by REST gateway to call own gRPC server when server TLS is enabled
+	GatewayTLS ClientTLS `envconfig:"gateway_tls"`
+
+	ProductClient      GRPCClient `envconfig:"product_client"`
+	PaymentClient      GRPCClient `envconfig:"payment_client"`
+	NotificationClient GRPCClient `envconfig:"notification_client"`
+
+	ProductCache ProductCache `envconfig:"product_cache"`
+	RateLimit    RateLimit    `envconfig:"rate_limit"`
+	Quotas       Quotas       `envconfig:"quotas"`
+
+	Activities  Activities  `envconfig:"activities"`
+	OrderExpiry OrderExpiry `envconfig:"order_expiry"`
+}
+
+const skipMigrateFlagName = "skip-migrate"
+
+var domainEventsExchange = libamqp.ExchangeConfig{
+	Name:    "domain_events",
+	Kind:    "topic",
+	Durable: true,
 }
 
 func service(logger logging.Logger) *cli.Command {
 	return &cli.Command{
-		Name:   "service",
-		Before: migrateImpl(logger),
-		Action: func(c *cli.Context) error {
+		Name: "service",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    skipMigrateFlagName,
+				Usage:   "don't apply migrations on start, schema version is still checked",
+				EnvVars: []string{"ORDER_SKIP_MIGRATE"},
+			},
+		},
+		Before: func(c *cli.Context) error {
+			if c.Bool(skipMigrateFlagName) {
+				logger.Info("migrations are skipped")
+				return nil
+			}
+			return migrateImpl(logger)(c)
+		},
+		Action: func(c *cli.Context) (err error) {
 			cnf, err := parseEnvs[serviceConfig]()
 			if err != nil {
 				return err
 			}
 
+			shutdownTracing, err := tracing.Setup(c.Context, appID, tracing.Config{
+				Exporter:     cnf.Tracing.Exporter,
+				OTLPEndpoint: cnf.Tracing.OTLPEndpoint,
+				SampleRatio:  cnf.Tracing.SampleRatio,
+			})
+			if err != nil {
+				return err
+			}
+			// tracing is shut down after all other closers to flush their spans
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), cnf.Service.GracePeriod)
+				defer cancel()
+				err = errors.Join(err, shutdownTracing(ctx))
+			}()
+
 			closer := libio.NewMultiCloser()
 			defer func() {
 				err = errors.Join(err, closer.Close())
@@ -57,12 +128,28 @@ func service(logger logging.Logger) *cli.Command {
 			closer.AddCloser(databaseConnector)
 			databaseConnectionPool := mysql.NewConnectionPool(databaseConnector.TransactionalClient())
 
+			schemaVersion, err := checkSchemaVersion(c.Context, databaseConnectionPool, logger)
+			if err != nil {
+				return err
+			}
+
+			serviceMetrics := metrics.NewMetrics()
+
 			libUoW := mysql.NewUnitOfWork(databaseConnectionPool, inframysql.NewRepositoryProvider)
-			uow := inframysql.NewUnitOfWork(libUoW)
+			orderEventBroker := appservice.NewOrderEventBroker()
+			uow := appservice.NewBroadcastingUnitOfWork(
+				metrics.NewUnitOfWork(inframysql.NewUnitOfWork(libUoW, logger), serviceMetrics),
+				orderEventBroker,
+			)
 
-			productConn, err := grpc.NewClient(
+			productConn, productBreaker, err := dialService(
+				"product",
 				cnf.Service.ProductServiceAddress,
-				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				cnf.ProductTLS,
+				cnf.ProductClient,
+				true,
+				serviceMetrics,
+				logger,
 			)
 			if err != nil {
 				return err
@@ -70,21 +157,44 @@ func service(logger logging.Logger) *cli.Command {
 			closer.AddCloser(productConn)
 
 			productClient := client.NewProductClient(productConn)
+			var productService appservice.ProductService = productClient
+			var priceCache *cache.ProductPriceCache
+			if cnf.ProductCache.Enabled {
+				priceCache = cache.NewProductPriceCache(productClient, cache.Config{
+					TTL:     cnf.ProductCache.TTL,
+					MaxSize: cnf.ProductCache.MaxSize,
+				})
+				productService = priceCache
+				if cnf.ProductCache.Warmup {
+					warmupProductCache(c.Context, priceCache, productClient, cnf.ProductCache.WarmupTimeout, logger)
+				}
+			}
 
-			paymentConn, err := grpc.NewClient(
+			// payment is not idempotent, retry could charge customer twice
+			paymentConn, paymentBreaker, err := dialService(
+				"payment",
 				cnf.Service.PaymentServiceAddress,
-				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				cnf.PaymentTLS,
+				cnf.PaymentClient,
+				false,
+				serviceMetrics,
+				logger,
 			)
 			if err != nil {
 				return err
 			}
 			closer.AddCloser(paymentConn)
 
-			paymentClient := client.NewPaymentClient(paymentConn)
+			paymentClient := metrics.NewPaymentService(client.NewPaymentClient(paymentConn), serviceMetrics)
 
-			notificationConn, err := grpc.NewClient(
+			notificationConn, notificationBreaker, err := dialService(
+				"notification",
 				cnf.Service.NotificationServiceAddress,
-				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				cnf.NotificationTLS,
+				cnf.NotificationClient,
+				true,
+				serviceMetrics,
+				logger,
 			)
 			if err != nil {
 				return err
@@ -94,15 +204,17 @@ func service(logger logging.Logger) *cli.Command {
 			notificationClient := client.NewNotificationClient(notificationConn)
 
 			amqpConnection := newAMQPConnection(cnf.AMQP, logger)
-			amqpProducer := amqpConnection.Producer(
-				&libamqp.ExchangeConfig{
-					Name:    "domain_events",
-					Kind:    "topic",
-					Durable: true,
-				},
-				nil,
-				nil,
-			)
+			amqpProducer := infraamqp.NewProducer(appID, domainEventsExchange, logger)
+			amqpConnection.AddChannel(amqpProducer)
+			var productUpdatesConsumer *infraamqp.Consumer
+			if priceCache != nil {
+				// every instance has own queue, so all of them invalidate their caches
+				productUpdatesConsumer = infraamqp.NewConsumer(domainEventsExchange, libamqp.QueueConfig{
+					Exclusive:  true,
+					AutoDelete: true,
+				}, []string{infraamqp.ProductUpdatedRoutingKey}, infraamqp.NewProductUpdatedHandler(priceCache.Invalidate), logger)
+				amqpConnection.AddChannel(productUpdatesConsumer)
+			}
 			err = amqpConnection.Start()
 			if err != nil {
 				return err
@@ -111,11 +223,17 @@ func service(logger logging.Logger) *cli.Command {
 				return amqpConnection.Stop()
 			}))
 
-			eventPublisher := infraamqp.NewEventPublisher(amqpProducer)
+			eventPublisher := metrics.NewEventPublisher(infraamqp.NewEventPublisher(amqpProducer), serviceMetrics)
 
 			// Temporal Setup
+			temporalTracingInterceptor, err := temporalotel.NewTracingInterceptor(temporalotel.TracerOptions{})
+			if err != nil {
+				return err
+			}
+			// client interceptors are applied to workers created with the client too
 			temporalClient, err := temporalclient.Dial(temporalclient.Options{
-				HostPort: cnf.Service.TemporalAddress,
+				HostPort:     cnf.Service.TemporalAddress,
+				Interceptors: []interceptor.ClientInterceptor{temporalTracingInterceptor},
 			})
 			if err != nil {
 				return err
@@ -125,12 +243,36 @@ func service(logger logging.Logger) *cli.Command {
 				return nil
 			}))
 
-			workflowStarter := infratemporal.NewWorkflowStarter(temporalClient)
+			activityOptionsByName := map[string]infratemporal.ActivityOptions{
+				infratemporal.CreateOrderActivityName:       activityOptions(infratemporal.CreateOrderActivityName, cnf.Activities.CreateOrder),
+				infratemporal.GetProductPriceActivityName:   activityOptions(infratemporal.GetProductPriceActivityName, cnf.Activities.GetProductPrice),
+				infratemporal.AddItemActivityName:           activityOptions(infratemporal.AddItemActivityName, cnf.Activities.AddItem),
+				infratemporal.ProcessPaymentActivityName:    activityOptions(infratemporal.ProcessPaymentActivityName, cnf.Activities.ProcessPayment),
+				infratemporal.MarkOrderPaidActivityName:     activityOptions(infratemporal.MarkOrderPaidActivityName, cnf.Activities.MarkOrderPaid),
+				infratemporal.SendNotificationActivityName:  activityOptions(infratemporal.SendNotificationActivityName, cnf.Activities.SendNotification),
+				infratemporal.FindExpiredOrdersActivityName: activityOptions(infratemporal.FindExpiredOrdersActivityName, cnf.Activities.FindExpiredOrders),
+				infratemporal.ExpireOrderActivityName:       activityOptions(infratemporal.ExpireOrderActivityName, cnf.Activities.ExpireOrder),
+			}
+			workflowStarter := infratemporal.NewWorkflowStarter(temporalClient, activityOptionsByName)
 
-			activities := infratemporal.NewActivities(uow, productClient, paymentClient, notificationClient)
+			quotaChecker := appservice.NewQuotaChecker(appservice.QuotaConfig{
+				MaxOpenOrders:    cnf.Quotas.MaxOpenOrders,
+				MaxOrdersPerHour: cnf.Quotas.MaxOrdersPerHour,
+			})
+			activities := infratemporal.NewActivities(
+				uow,
+				productService,
+				paymentClient,
+				notificationClient,
+				query.NewExpiredOrderQuery(databaseConnector.TransactionalClient()),
+				quotaChecker,
+			)
 
-			w := worker.New(temporalClient, infratemporal.TaskQueue, worker.Options{})
+			w := worker.New(temporalClient, infratemporal.TaskQueue, worker.Options{
+				Interceptors: []interceptor.WorkerInterceptor{metrics.NewWorkerInterceptor(serviceMetrics)},
+			})
 			w.RegisterWorkflow(infratemporal.CreateOrderWorkflow)
+			w.RegisterWorkflow(infratemporal.ExpireOrdersWorkflow)
 			w.RegisterActivity(activities)
 
 			err = w.Start()
@@ -142,30 +284,149 @@ func service(logger logging.Logger) *cli.Command {
 				return nil
 			}))
 
+			if cnf.OrderExpiry.Enabled {
+				err = infratemporal.EnsureExpireOrdersSchedule(c.Context, temporalClient, infratemporal.ExpireOrdersScheduleConfig{
+					Interval: cnf.OrderExpiry.Interval,
+					Input: infratemporal.ExpireOrdersWorkflowInput{
+						TTL:             cnf.OrderExpiry.TTL,
+						BatchSize:       cnf.OrderExpiry.BatchSize,
+						ActivityOptions: activityOptionsByName,
+					},
+				})
+			} else {
+				logger.Info("order expiry is disabled")
+				err = infratemporal.DeleteExpireOrdersSchedule(c.Context, temporalClient)
+			}
+			if err != nil {
+				return err
+			}
+
+			readiness := health.NewChecker(health.Config{
+				Timeout:  cnf.Service.HealthCheckTimeout,
+				CacheTTL: cnf.Service.HealthCheckCacheTTL,
+			})
+			readiness.Register("database", health.DatabaseCheck(databaseConnector.TransactionalClient()))
+			readiness.Register("amqp", amqpProducer.Ready)
+			if productUpdatesConsumer != nil {
+				readiness.Register("amqp-product-updates", productUpdatesConsumer.Ready)
+			}
+			readiness.Register("temporal", health.TemporalCheck(temporalClient))
+			readiness.Register("product-service", health.GRPCConnectionCheck(productConn))
+			readiness.Register("product-service-breaker", productBreaker.Ready)
+			readiness.Register("payment-service", health.GRPCConnectionCheck(paymentConn))
+			readiness.Register("payment-service-breaker", paymentBreaker.Ready)
+			readiness.Register("notification-service", health.GRPCConnectionCheck(notificationConn))
+			readiness.Register("notification-service-breaker", notificationBreaker.Ready)
+			liveness := health.NewChecker(health.Config{})
+
 			orderInternalAPI := transport.NewOrderInternalAPI(
-				query.NewOrderQueryService(databaseConnector.TransactionalClient()),
-				appservice.NewOrderService(uow, productClient, eventPublisher, workflowStarter),
+				appservice.NewOrderQueryService(query.NewOrderQuery(databaseConnector.TransactionalClient())),
+				appservice.NewOrderService(uow, productService, eventPublisher, workflowStarter, quotaChecker),
+				appservice.NewOrderWatchService(uow, orderEventBroker),
+				cnf.Service.WatchHeartbeatInterval,
 			)
 
+			router := mux.NewRouter()
+			registerHealthcheck(router, schemaVersion, readiness, liveness)
+			router.Handle("/metrics", serviceMetrics.Handler())
+			gatewayCredentials, err := newClientCredentials(cnf.GatewayTLS, logger)
+			if err != nil {
+				return err
+			}
+			gatewayConn, err := registerGateway(c.Context, router, cnf.Service.GRPCAddress, gatewayCredentials)
+			if err != nil {
+				return err
+			}
+			closer.AddCloser(gatewayConn)
+
+			unaryInterceptors := []grpc.UnaryServerInterceptor{
+				middlewares.NewGRPCLoggingMiddleware(logger),
+				middlewares.NewGRPCMetricsMiddleware(serviceMetrics),
+				transport.NewGRPCErrorMiddleware(),
+			}
+			streamInterceptors := []grpc.StreamServerInterceptor{
+				transport.NewGRPCErrorStreamMiddleware(),
+			}
+			if cnf.Auth.Enabled {
+				verifier, err := auth.NewVerifier(auth.Config{
+					JWKSFile: cnf.Auth.JWKSFile,
+					JWKS:     cnf.Auth.JWKS,
+					Issuer:   cnf.Auth.Issuer,
+					Audience: cnf.Auth.Audience,
+					Leeway:   cnf.Auth.Leeway,
+				})
+				if err != nil {
+					return err
+				}
+				unaryInterceptors = append(unaryInterceptors, middlewares.NewGRPCAuthMiddleware(
+					verifier,
+					"/"+healthpb.Health_ServiceDesc.ServiceName+"/",
+					"/grpc.reflection.",
+				))
+				streamInterceptors = append(streamInterceptors, middlewares.NewGRPCAuthStreamMiddleware(
+					verifier,
+					"/"+healthpb.Health_ServiceDesc.ServiceName+"/",
+					"/grpc.reflection.",
+				))
+			} else {
+				logger.Info("authentication is disabled, user ID is taken from requests")
+			}
+			if cnf.RateLimit.Enabled {
+				rateLimitConfig, err := newRateLimitConfig(cnf.RateLimit)
+				if err != nil {
+					return err
+				}
+				if !cnf.Auth.Enabled && !rateLimitConfig.ByAddress && !rateLimitConfig.LimitUnknownCallers {
+					logger.Info("authentication and rate limiting by address are disabled, requests are not rate limited")
+				}
+				unaryInterceptors = append(unaryInterceptors, middlewares.NewGRPCRateLimitMiddleware(rateLimitConfig))
+				// streams have only default limit
+				rateLimitConfig.Methods = nil
+				streamInterceptors = append(streamInterceptors, middlewares.NewGRPCRateLimitStreamMiddleware(rateLimitConfig))
+			}
+			unaryInterceptors = append(unaryInterceptors, middlewares.NewGRPCInitiatorMiddleware())
+
+			serverCredentials, err := newServerCredentials(cnf.ServerTLS, logger)
+			if err != nil {
+				return err
+			}
+
 			errGroup := errgroup.Group{}
 			errGroup.Go(func() error {
 				listener, err := net.Listen("tcp", cnf.Service.GRPCAddress)
 				if err != nil {
 					return err
 				}
-				grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
-					middlewares.NewGRPCLoggingMiddleware(logger),
-				))
+				grpcServer := grpc.NewServer(
+					grpc.Creds(serverCredentials),
+					grpc.StatsHandler(otelgrpc.NewServerHandler()),
+					grpc.ChainUnaryInterceptor(unaryInterceptors...),
+					grpc.ChainStreamInterceptor(streamInterceptors...),
+				)
 				orderinternal.RegisterOrderInternalServiceServer(grpcServer, orderInternalAPI)
+
+				healthServer := grpchealth.NewServer()
+				healthpb.RegisterHealthServer(grpcServer, healthServer)
+				go health.UpdateGRPCServingStatus(
+					c.Context,
+					readiness,
+					healthServer,
+					cnf.Service.HealthCheckInterval,
+					orderinternal.OrderInternalService_ServiceDesc.ServiceName,
+				)
+				if cnf.Service.GRPCReflection {
+					reflection.Register(grpcServer)
+				}
+
 				graceCallback(c.Context, logger, cnf.Service.GracePeriod, func(_ context.Context) error {
+					// clients stop routing new calls before server stops accepting them
+					healthServer.Shutdown()
 					grpcServer.GracefulStop()
 					return nil
 				})
 				return grpcServer.Serve(listener)
 			})
 			errGroup.Go(func() error {
-				router := mux.NewRouter()
-				registerHealthcheck(router)
 				// nolint:gosec
 				server := http.Server{
 					Addr:    cnf.Service.HTTPAddress,
@@ -179,3 +440,138 @@ func service(logger logging.Logger) *cli.Command {
 		},
 	}
 }
+
+func newServerCredentials(config ServerTLS, logger logging.Logger) (credentials.TransportCredentials, error) {
+	if !config.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	return tlscredentials.NewServerCredentials(tlscredentials.ServerConfig{
+		CertFile:          config.CertFile,
+		KeyFile:           config.KeyFile,
+		ClientCAFile:      config.ClientCAFile,
+		RequireClientCert: config.RequireClientCert,
+	}, logger)
+}
+
+func newRateLimitConfig(config RateLimit) (middlewares.RateLimitConfig, error) {
+	trustedProxies := make([]*net.IPNet, 0, len(config.TrustedProxies))
+	for _, cidr := range config.TrustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return middlewares.RateLimitConfig{}, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		trustedProxies = append(trustedProxies, network)
+	}
+
+	createOrderLimit := middlewares.RateLimit{RPS: config.CreateOrderRPS, Burst: config.CreateOrderBurst}
+	return middlewares.RateLimitConfig{
+		Default: middlewares.RateLimit{RPS: config.RPS, Burst: config.Burst},
+		Methods: map[string]middlewares.RateLimit{
+			orderinternal.OrderInternalService_CreateOrder_FullMethodName:      createOrderLimit,
+			orderinternal.OrderInternalService_CreateOrderAsync_FullMethodName: createOrderLimit,
+		},
+		ByAddress:           config.ByAddress,
+		TrustedProxies:      trustedProxies,
+		LimitUnknownCallers: config.LimitUnknownCallers,
+		SkipPrefixes: []string{
+			"/" + healthpb.Health_ServiceDesc.ServiceName + "/",
+			"/grpc.reflection.",
+		},
+	}, nil
+}
+
+func activityOptions(name string, config Activity) infratemporal.ActivityOptions {
+	maximumAttempts := infratemporal.DefaultMaximumAttempts(name)
+	if config.MaximumAttempts != nil {
+		maximumAttempts = *config.MaximumAttempts
+	}
+	return infratemporal.ActivityOptions{
+		StartToCloseTimeout:    config.StartToCloseTimeout,
+		ScheduleToCloseTimeout: config.ScheduleToCloseTimeout,
+		InitialInterval:        config.InitialInterval,
+		BackoffCoefficient:     config.BackoffCoefficient,
+		MaximumInterval:        config.MaximumInterval,
+		MaximumAttempts:        maximumAttempts,
+	}
+}
+
+func newClientCredentials(config ClientTLS, logger logging.Logger) (credentials.TransportCredentials, error) {
+	if !config.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	return tlscredentials.NewClientCredentials(tlscredentials.ClientConfig{
+		CAFile:     config.CAFile,
+		CertFile:   config.CertFile,
+		KeyFile:    config.KeyFile,
+		ServerName: config.ServerName,
+	}, logger)
+}
+
+// dialService connects to downstream service with deadlines, retries and circuit breaker,
+// breaker is applied after retries so it counts only calls failed with all attempts
+func dialService(
+	name string,
+	address string,
+	tlsConfig ClientTLS,
+	clientConfig GRPCClient,
+	retries bool,
+	serviceMetrics *metrics.Metrics,
+	logger logging.Logger,
+) (*grpc.ClientConn, *client.CircuitBreaker, error) {
+	creds, err := newClientCredentials(tlsConfig, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxAttempts := clientConfig.MaxAttempts
+	if !retries {
+		maxAttempts = 1
+	}
+	serviceConfig, err := client.ServiceConfig(client.Config{
+		Timeout:        clientConfig.Timeout,
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: clientConfig.InitialBackoff,
+		MaxBackoff:     clientConfig.MaxBackoff,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	breaker := client.NewCircuitBreaker(name, client.CircuitBreakerConfig{
+		MaxFailures: clientConfig.BreakerMaxFailures,
+		OpenTimeout: clientConfig.BreakerOpenTimeout,
+	}, serviceMetrics.SetCircuitBreakerState)
+	serviceMetrics.SetCircuitBreakerState(name, 0)
+
+	conn, err := grpc.NewClient(
+		address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(
+			middlewares.NewGRPCClientMetricsMiddleware(serviceMetrics, name),
+			breaker.Interceptor(),
+		),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, breaker, nil
+}
+
+func warmupProductCache(
+	ctx context.Context,
+	priceCache *cache.ProductPriceCache,
+	lister cache.ProductLister,
+	timeout time.Duration,
+	logger logging.Logger,
+) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	count, err := priceCache.Warmup(ctx, lister)
+	if err != nil {
+		logger.Error(err, "failed to warm product cache up, prices are loaded on demand")
+		return
+	}
+	logger.Info(fmt.Sprintf("product cache is warmed up with %d prices", count))
+}
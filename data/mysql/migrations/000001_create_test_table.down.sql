@@ -1 +0,0 @@
-DROP TABLE IF EXISTS test_table;
\ No newline at end of file
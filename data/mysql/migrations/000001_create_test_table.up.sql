@@ -1,10 +0,0 @@
-CREATE TABLE IF NOT EXISTS test_table
-(
-    `id`         INT AUTO_INCREMENT,
-    `message`    VARCHAR(255) NOT NULL,
-    `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    PRIMARY KEY (`id`)
-) ENGINE = InnoDB
-  CHARACTER SET = utf8mb4
-  COLLATE utf8mb4_unicode_ci
-;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    DROP INDEX idx_status_created_at,
+    ADD INDEX idx_status (status);
@@ -0,0 +1,4 @@
+-- expired orders are looked up by status and creation time, index by status alone becomes its prefix
+ALTER TABLE orders
+    DROP INDEX idx_status,
+    ADD INDEX idx_status_created_at (status, created_at);
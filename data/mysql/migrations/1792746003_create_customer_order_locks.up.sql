@@ -0,0 +1,9 @@
+-- row per customer is locked by transactions creating orders, so quota checks of one customer don't race
+CREATE TABLE customer_order_locks
+(
+    user_id BINARY(16) NOT NULL,
+    PRIMARY KEY (user_id)
+)
+    ENGINE = InnoDB
+    CHARACTER SET = utf8mb4
+    COLLATE utf8mb4_unicode_ci;
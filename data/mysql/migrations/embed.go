@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS holds versioned SQL migrations named as <version>_<description>.up.sql and <version>_<description>.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
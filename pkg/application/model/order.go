@@ -1,6 +1,10 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Order struct {
 	UserID uuid.UUID
@@ -11,3 +15,30 @@ type OrderItem struct {
 	ProductID uuid.UUID
 	Quantity  int
 }
+
+// OrderDetails is order as it is shown to clients
+type OrderDetails struct {
+	OrderID    uuid.UUID
+	UserID     uuid.UUID
+	Status     string
+	TotalPrice float64
+	Items      []OrderDetailsItem
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type OrderDetailsItem struct {
+	ProductID uuid.UUID
+	Quantity  int
+	Price     float64
+}
+
+// OrderHistoryEvent is order event with its payload serialized to JSON
+type OrderHistoryEvent struct {
+	EventID    int64
+	Type       string
+	Payload    string
+	Actor      string
+	Source     string
+	OccurredAt time.Time
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"order/pkg/domain/model"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+	// RoleService is granted to other services calling on behalf of any customer
+	RoleService = "service"
+)
+
+// AuthorizeOrderRead allows customer to read own orders, staff and services can read any order
+func AuthorizeOrderRead(ctx context.Context, customerID uuid.UUID) error {
+	return authorizeOrder(ctx, customerID, RoleSupport, RoleAdmin, RoleService)
+}
+
+// authorizeOrderVisible reports order caller may not read as not found, so existence of other customers orders can't be probed
+func authorizeOrderVisible(ctx context.Context, customerID uuid.UUID) error {
+	err := AuthorizeOrderRead(ctx, customerID)
+	if errors.Is(err, ErrPermissionDenied) {
+		return model.ErrOrderNotFound
+	}
+	return err
+}
+
+// AuthorizeOrderWrite allows customer to modify own orders, support has read only access
+func AuthorizeOrderWrite(ctx context.Context, customerID uuid.UUID) error {
+	return authorizeOrder(ctx, customerID, RoleAdmin, RoleService)
+}
+
+// authorizeOrder allows everything for unauthenticated caller since identity is absent only when authentication is disabled
+func authorizeOrder(ctx context.Context, customerID uuid.UUID, anyOrderRoles ...string) error {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, role := range anyOrderRoles {
+		if identity.HasRole(role) {
+			return nil
+		}
+	}
+	if identity.UserID == customerID {
+		return nil
+	}
+	return ErrPermissionDenied
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Identity is authenticated caller, its user ID is trusted over user ID passed in requests
+type Identity struct {
+	UserID  uuid.UUID
+	Subject string
+	Roles   []string
+}
+
+func (i Identity) HasRole(role string) bool {
+	return slices.Contains(i.Roles, role)
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns false if caller is not authenticated, e.g. when authentication is disabled
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 
@@ -15,6 +16,13 @@ type OrderService interface {
 	CreateOrderAsync(ctx context.Context, order appmodel.Order) (uuid.UUID, error)
 }
 
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrInvalidPaymentRequest means payment service rejected request itself, it is bug rather than business outcome
+	ErrInvalidPaymentRequest = errors.New("invalid payment request")
+)
+
 type ProductService interface {
 	GetPrice(ctx context.Context, productID uuid.UUID) (float64, error)
 }
@@ -40,12 +48,14 @@ func NewOrderService(
 	productService ProductService,
 	eventPublisher EventPublisher,
 	workflowStarter WorkflowStarter,
+	quotaChecker QuotaChecker,
 ) OrderService {
 	return &orderService{
 		uow:             uow,
 		productService:  productService,
 		eventPublisher:  eventPublisher,
 		workflowStarter: workflowStarter,
+		quotaChecker:    quotaChecker,
 	}
 }
 
@@ -54,25 +64,48 @@ type orderService struct {
 	productService  ProductService
 	eventPublisher  EventPublisher
 	workflowStarter WorkflowStarter
+	quotaChecker    QuotaChecker
 }
 
 type NoOpEventDispatcher struct{}
 
-func (d *NoOpEventDispatcher) Dispatch(event service.Event) error {
+func (d *NoOpEventDispatcher) Dispatch(_ context.Context, _ service.Event) error {
 	return nil
 }
 
+// CreateOrder rejects order over quota before workflow is started,
+// quota is checked again by workflow together with order creation
 func (s *orderService) CreateOrder(ctx context.Context, order appmodel.Order) (uuid.UUID, error) {
+	if err := AuthorizeOrderWrite(ctx, order.UserID); err != nil {
+		return uuid.Nil, err
+	}
+	err := s.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		return s.quotaChecker.CheckCreateOrder(ctx, provider.OrderCountQuery(), order.UserID)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
 	return s.workflowStarter.StartCreateOrderWorkflow(ctx, order)
 }
 
 func (s *orderService) CreateOrderAsync(ctx context.Context, order appmodel.Order) (uuid.UUID, error) {
+	if err := AuthorizeOrderWrite(ctx, order.UserID); err != nil {
+		return uuid.Nil, err
+	}
+
 	var orderID uuid.UUID
 	err := s.uow.Execute(ctx, func(provider RepositoryProvider) error {
-		domainService := service.NewOrderService(provider.OrderRepository(ctx), &NoOpEventDispatcher{})
+		err := s.quotaChecker.CheckCreateOrder(ctx, provider.OrderCountQuery(), order.UserID)
+		if err != nil {
+			return err
+		}
+
+		domainService := service.NewOrderService(
+			provider.OrderRepository(),
+			NewOrderHistoryDispatcher(provider.OrderEventRepository()),
+		)
 
-		var err error
-		orderID, err = domainService.CreateOrder(order.UserID)
+		orderID, err = domainService.CreateOrder(ctx, order.UserID)
 		if err != nil {
 			return err
 		}
@@ -88,7 +121,7 @@ func (s *orderService) CreateOrderAsync(ctx context.Context, order appmodel.Orde
 			totalAmount += price * float64(item.Quantity)
 
 			for i := 0; i < item.Quantity; i++ {
-				_, err = domainService.AddItem(orderID, item.ProductID, price)
+				_, err = domainService.AddItem(ctx, orderID, item.ProductID, price)
 				if err != nil {
 					return err
 				}
@@ -100,11 +133,9 @@ func (s *orderService) CreateOrderAsync(ctx context.Context, order appmodel.Orde
 			UserID:      order.UserID,
 			TotalAmount: totalAmount,
 		}
-		if err := s.eventPublisher.PublishOrderCreated(ctx, event); err != nil {
-			return err
-		}
-
-		return nil
+		return s.uow.AfterCommit(ctx, func(ctx context.Context) error {
+			return s.eventPublisher.PublishOrderCreated(ctx, event)
+		})
 	})
 	return orderID, err
 }
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExpiredOrderQuery finds orders which are still neither paid nor cancelled after their time-to-live
+type ExpiredOrderQuery interface {
+	// FindExpiredOrders returns open and pending orders created before createdBefore, the oldest first
+	FindExpiredOrders(ctx context.Context, createdBefore time.Time, limit int) ([]uuid.UUID, error)
+}
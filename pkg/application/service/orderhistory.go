@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+)
+
+const unknownInitiator = "unknown"
+
+// Initiator describes who and through which entrypoint changes an order
+type Initiator struct {
+	Actor  string
+	Source string
+}
+
+type initiatorKey struct{}
+
+func WithInitiator(ctx context.Context, initiator Initiator) context.Context {
+	return context.WithValue(ctx, initiatorKey{}, initiator)
+}
+
+func InitiatorFromContext(ctx context.Context) Initiator {
+	initiator, _ := ctx.Value(initiatorKey{}).(Initiator)
+	if initiator.Actor == "" {
+		initiator.Actor = unknownInitiator
+	}
+	if initiator.Source == "" {
+		initiator.Source = unknownInitiator
+	}
+	return initiator
+}
+
+// NewOrderHistoryDispatcher returns dispatcher appending every order event to order history
+// with initiator taken from dispatch context
+func NewOrderHistoryDispatcher(repo model.OrderEventRepository) domainservice.EventDispatcher {
+	return &orderHistoryDispatcher{
+		repo: repo,
+	}
+}
+
+type orderHistoryDispatcher struct {
+	repo model.OrderEventRepository
+}
+
+func (d *orderHistoryDispatcher) Dispatch(ctx context.Context, event domainservice.Event) error {
+	orderEvent, ok := event.(model.OrderEvent)
+	if !ok {
+		return errors.New("unsupported event " + event.Type())
+	}
+	initiator := InitiatorFromContext(ctx)
+	return d.repo.Append(ctx, &model.OrderEventRecord{
+		OrderID:    orderEvent.AggregateID(),
+		Event:      orderEvent,
+		Actor:      initiator.Actor,
+		Source:     initiator.Source,
+		OccurredAt: time.Now(),
+	})
+}
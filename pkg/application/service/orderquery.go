@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/domain/model"
+)
+
+// OrderQuery reads orders as they are shown to clients without any access checks
+type OrderQuery interface {
+	// GetOrder returns nil if order does not exist
+	GetOrder(ctx context.Context, orderID uuid.UUID) (*appmodel.OrderDetails, error)
+	GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]appmodel.OrderHistoryEvent, error)
+}
+
+// OrderQueryService reads orders on behalf of caller, orders caller may not read are reported as not found
+type OrderQueryService interface {
+	GetOrder(ctx context.Context, orderID uuid.UUID) (appmodel.OrderDetails, error)
+	GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]appmodel.OrderHistoryEvent, error)
+}
+
+func NewOrderQueryService(query OrderQuery) OrderQueryService {
+	return &orderQueryService{
+		query: query,
+	}
+}
+
+type orderQueryService struct {
+	query OrderQuery
+}
+
+func (s *orderQueryService) GetOrder(ctx context.Context, orderID uuid.UUID) (appmodel.OrderDetails, error) {
+	order, err := s.readableOrder(ctx, orderID)
+	if err != nil {
+		return appmodel.OrderDetails{}, err
+	}
+	return *order, nil
+}
+
+func (s *orderQueryService) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]appmodel.OrderHistoryEvent, error) {
+	_, err := s.readableOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.query.GetOrderHistory(ctx, orderID)
+}
+
+func (s *orderQueryService) readableOrder(ctx context.Context, orderID uuid.UUID) (*appmodel.OrderDetails, error) {
+	order, err := s.query.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, model.ErrOrderNotFound
+	}
+	err = authorizeOrderVisible(ctx, order.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/domain/model"
+)
+
+// ErrWatchLagged means watcher is disconnected because it does not keep up with events
+var ErrWatchLagged = errors.New("order watcher lags behind events, resume from last event")
+
+// watchBufferSize is count of events kept for slow watcher before it is disconnected
+const watchBufferSize = 64
+
+// OrderEventBroker delivers committed order events to watchers in this process
+type OrderEventBroker struct {
+	mu       sync.Mutex
+	watchers map[uuid.UUID]map[*orderWatcher]struct{}
+}
+
+type orderWatcher struct {
+	events chan model.OrderEventRecord
+}
+
+func NewOrderEventBroker() *OrderEventBroker {
+	return &OrderEventBroker{
+		watchers: make(map[uuid.UUID]map[*orderWatcher]struct{}),
+	}
+}
+
+// Publish never blocks, watcher with full buffer is disconnected and has to resume from its last event
+func (b *OrderEventBroker) Publish(record model.OrderEventRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for watcher := range b.watchers[record.OrderID] {
+		select {
+		case watcher.events <- record:
+		default:
+			b.removeLocked(record.OrderID, watcher)
+		}
+	}
+}
+
+func (b *OrderEventBroker) subscribe(orderID uuid.UUID) *orderWatcher {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	watcher := &orderWatcher{events: make(chan model.OrderEventRecord, watchBufferSize)}
+	if b.watchers[orderID] == nil {
+		b.watchers[orderID] = make(map[*orderWatcher]struct{})
+	}
+	b.watchers[orderID][watcher] = struct{}{}
+	return watcher
+}
+
+func (b *OrderEventBroker) unsubscribe(orderID uuid.UUID, watcher *orderWatcher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(orderID, watcher)
+}
+
+func (b *OrderEventBroker) removeLocked(orderID uuid.UUID, watcher *orderWatcher) {
+	watchers := b.watchers[orderID]
+	if _, ok := watchers[watcher]; !ok {
+		return
+	}
+	delete(watchers, watcher)
+	if len(watchers) == 0 {
+		delete(b.watchers, orderID)
+	}
+	close(watcher.events)
+}
+
+// NewBroadcastingUnitOfWork publishes appended order events to broker once their transaction is committed
+func NewBroadcastingUnitOfWork(uow UnitOfWork, broker *OrderEventBroker) UnitOfWork {
+	return &broadcastingUnitOfWork{
+		UnitOfWork: uow,
+		broker:     broker,
+	}
+}
+
+type broadcastingUnitOfWork struct {
+	UnitOfWork
+	broker *OrderEventBroker
+}
+
+func (u *broadcastingUnitOfWork) Execute(ctx context.Context, f func(provider RepositoryProvider) error) error {
+	return u.UnitOfWork.Execute(ctx, func(provider RepositoryProvider) error {
+		return f(&broadcastingRepositoryProvider{
+			RepositoryProvider: provider,
+			uow:                u,
+		})
+	})
+}
+
+type broadcastingRepositoryProvider struct {
+	RepositoryProvider
+	uow *broadcastingUnitOfWork
+}
+
+func (p *broadcastingRepositoryProvider) OrderEventRepository() model.OrderEventRepository {
+	return &broadcastingOrderEventRepository{
+		OrderEventRepository: p.RepositoryProvider.OrderEventRepository(),
+		uow:                  p.uow,
+	}
+}
+
+type broadcastingOrderEventRepository struct {
+	model.OrderEventRepository
+	uow *broadcastingUnitOfWork
+}
+
+func (r *broadcastingOrderEventRepository) Append(ctx context.Context, record *model.OrderEventRecord) error {
+	err := r.OrderEventRepository.Append(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	appended := *record
+	// watchers resume from history, so event outside of unit of work is just not broadcast
+	_ = r.uow.AfterCommit(ctx, func(context.Context) error {
+		r.uow.broker.Publish(appended)
+		return nil
+	})
+	return nil
+}
+
+// OrderWatch is order state at the moment watching started followed by events committed after it
+type OrderWatch struct {
+	// Snapshot is set only when watching is not resumed, it is read by the same query as order is read by OrderQueryService
+	Snapshot *appmodel.OrderDetails
+	// Missed are events after last seen event when watching is resumed
+	Missed []model.OrderEventRecord
+	// LastEventID is the last event reflected in Snapshot, Missed or events returned by CatchUp
+	LastEventID int64
+
+	uow     UnitOfWork
+	broker  *OrderEventBroker
+	orderID uuid.UUID
+	watcher *orderWatcher
+}
+
+// Events returns channel notifying about committed events, they are sent to watcher as returned by CatchUp.
+// Channel is closed when watcher lags behind, watching should be resumed from the last received event then
+func (w *OrderWatch) Events() <-chan model.OrderEventRecord {
+	return w.watcher.events
+}
+
+// CatchUp returns events after LastEventID up to received one in order of IDs and moves LastEventID to the last of them.
+// Events are broadcast once their commit hooks run, so event with lower ID may be received after event with higher ID.
+// History has no gaps in committed events though, events of order are appended after order row is locked by its store
+func (w *OrderWatch) CatchUp(ctx context.Context, received model.OrderEventRecord) ([]model.OrderEventRecord, error) {
+	if received.ID <= w.LastEventID {
+		// already read from history
+		return nil, nil
+	}
+
+	var history []model.OrderEventRecord
+	err := w.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		var err error
+		history, err = provider.OrderEventRepository().FindByOrderID(ctx, w.orderID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []model.OrderEventRecord
+	for _, record := range history {
+		if record.ID > w.LastEventID {
+			records = append(records, record)
+		}
+	}
+	if len(records) > 0 {
+		w.LastEventID = records[len(records)-1].ID
+	}
+	return records, nil
+}
+
+func (w *OrderWatch) Close() {
+	w.broker.unsubscribe(w.orderID, w.watcher)
+}
+
+type OrderWatchService interface {
+	WatchOrder(ctx context.Context, orderID uuid.UUID, lastEventID int64) (*OrderWatch, error)
+}
+
+func NewOrderWatchService(uow UnitOfWork, broker *OrderEventBroker) OrderWatchService {
+	return &orderWatchService{
+		uow:    uow,
+		broker: broker,
+	}
+}
+
+type orderWatchService struct {
+	uow    UnitOfWork
+	broker *OrderEventBroker
+}
+
+func (s *orderWatchService) WatchOrder(ctx context.Context, orderID uuid.UUID, lastEventID int64) (*OrderWatch, error) {
+	// subscription goes first, so events committed while order is loaded are not lost
+	watch := &OrderWatch{
+		uow:     s.uow,
+		broker:  s.broker,
+		orderID: orderID,
+		watcher: s.broker.subscribe(orderID),
+	}
+
+	var (
+		order   *appmodel.OrderDetails
+		history []model.OrderEventRecord
+	)
+	// order and its history are read in one transaction to be consistent with each other
+	err := s.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		var err error
+		order, err = provider.OrderQuery().GetOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return model.ErrOrderNotFound
+		}
+		history, err = provider.OrderEventRepository().FindByOrderID(ctx, orderID)
+		return err
+	})
+	if err == nil {
+		err = authorizeOrderVisible(ctx, order.UserID)
+	}
+	if err != nil {
+		watch.Close()
+		return nil, err
+	}
+
+	watch.LastEventID = lastEventID
+	if lastEventID == 0 {
+		watch.Snapshot = order
+	}
+	for _, record := range history {
+		if record.ID <= lastEventID {
+			continue
+		}
+		if lastEventID != 0 {
+			watch.Missed = append(watch.Missed, record)
+		}
+		watch.LastEventID = max(watch.LastEventID, record.ID)
+	}
+	return watch, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrQuotaExceeded = errors.New("order quota exceeded")
+
+// QuotaExceededError tells which quota is exceeded and when customer may retry, zero RetryAfter means unknown
+type QuotaExceededError struct {
+	Quota      string
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrQuotaExceeded, e.Quota)
+}
+
+// Cause makes error mapped as ErrQuotaExceeded by errors.Cause
+func (e *QuotaExceededError) Cause() error {
+	return ErrQuotaExceeded
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// OrderCountQuery counts customer orders for quotas
+type OrderCountQuery interface {
+	// LockCustomerOrders serializes transactions creating orders of customer until transaction ends,
+	// it must be called before counting, so counts include orders committed by concurrent transactions
+	LockCustomerOrders(ctx context.Context, customerID uuid.UUID) error
+	// CountOpenOrders counts orders which are not paid or cancelled yet
+	CountOpenOrders(ctx context.Context, customerID uuid.UUID) (int, error)
+	// CountOrdersSince counts orders created since given time and returns creation time of the oldest of them
+	CountOrdersSince(ctx context.Context, customerID uuid.UUID, since time.Time) (int, time.Time, error)
+}
+
+// QuotaConfig limits are disabled with zero values
+type QuotaConfig struct {
+	MaxOpenOrders    int
+	MaxOrdersPerHour int
+}
+
+type QuotaChecker interface {
+	// CheckCreateOrder must run in transaction creating order with query bound to it,
+	// customer orders stay locked till the end of transaction
+	CheckCreateOrder(ctx context.Context, query OrderCountQuery, customerID uuid.UUID) error
+}
+
+func NewQuotaChecker(config QuotaConfig) QuotaChecker {
+	return &quotaChecker{
+		config: config,
+	}
+}
+
+type quotaChecker struct {
+	config QuotaConfig
+}
+
+func (c *quotaChecker) CheckCreateOrder(ctx context.Context, query OrderCountQuery, customerID uuid.UUID) error {
+	if c.config.MaxOpenOrders <= 0 && c.config.MaxOrdersPerHour <= 0 {
+		return nil
+	}
+	err := query.LockCustomerOrders(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	if c.config.MaxOpenOrders > 0 {
+		count, err := query.CountOpenOrders(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if count >= c.config.MaxOpenOrders {
+			return &QuotaExceededError{Quota: "open orders"}
+		}
+	}
+
+	if c.config.MaxOrdersPerHour > 0 {
+		now := time.Now()
+		count, oldest, err := query.CountOrdersSince(ctx, customerID, now.Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		if count >= c.config.MaxOrdersPerHour {
+			// the oldest order leaves the window first
+			return &QuotaExceededError{
+				Quota:      "orders per hour",
+				RetryAfter: max(oldest.Add(time.Hour).Sub(now), time.Second),
+			}
+		}
+	}
+	return nil
+}
@@ -2,16 +2,29 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"order/pkg/domain/model"
 )
 
+var ErrNoActiveUnitOfWork = errors.New("no active unit of work")
+
 type RepositoryProvider interface {
-	OrderRepository(ctx context.Context) model.OrderRepository
+	OrderRepository() model.OrderRepository
+	OrderEventRepository() model.OrderEventRepository
+	OrderCountQuery() OrderCountQuery
+	OrderQuery() OrderQuery
 }
 
+// PostCommitHook is called once the transaction it was registered in has been committed.
+// Its error is only logged, committed unit of work is not reported as failed
+type PostCommitHook func(ctx context.Context) error
+
 type UnitOfWork interface {
 	Execute(ctx context.Context, f func(provider RepositoryProvider) error) error
+	// AfterCommit registers hook for the unit of work executing with ctx.
+	// Hooks are dropped if the transaction is rolled back or fails to commit
+	AfterCommit(ctx context.Context, hook PostCommitHook) error
 }
 
 type LockableUnitOfWork interface {
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+)
+
+func TestAuthorizeOrder(t *testing.T) {
+	customerID := uuid.Must(uuid.NewV7())
+	otherCustomerID := uuid.Must(uuid.NewV7())
+	withRoles := func(userID uuid.UUID, roles ...string) context.Context {
+		return service.WithIdentity(context.Background(), service.Identity{UserID: userID, Roles: roles})
+	}
+
+	t.Run("Customer reads and modifies only own orders", func(t *testing.T) {
+		ctx := withRoles(customerID, service.RoleCustomer)
+		require.NoError(t, service.AuthorizeOrderRead(ctx, customerID))
+		require.NoError(t, service.AuthorizeOrderWrite(ctx, customerID))
+		require.ErrorIs(t, service.AuthorizeOrderRead(ctx, otherCustomerID), service.ErrPermissionDenied)
+		require.ErrorIs(t, service.AuthorizeOrderWrite(ctx, otherCustomerID), service.ErrPermissionDenied)
+	})
+
+	t.Run("Support reads any order but modifies only own", func(t *testing.T) {
+		ctx := withRoles(customerID, service.RoleSupport)
+		require.NoError(t, service.AuthorizeOrderRead(ctx, otherCustomerID))
+		require.ErrorIs(t, service.AuthorizeOrderWrite(ctx, otherCustomerID), service.ErrPermissionDenied)
+	})
+
+	t.Run("Admin and services access any order", func(t *testing.T) {
+		for _, role := range []string{service.RoleAdmin, service.RoleService} {
+			ctx := withRoles(customerID, role)
+			require.NoError(t, service.AuthorizeOrderRead(ctx, otherCustomerID), role)
+			require.NoError(t, service.AuthorizeOrderWrite(ctx, otherCustomerID), role)
+		}
+	})
+
+	t.Run("Unauthenticated caller is not restricted", func(t *testing.T) {
+		require.NoError(t, service.AuthorizeOrderWrite(context.Background(), otherCustomerID))
+	})
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+func TestOrderQueryService(t *testing.T) {
+	customerID := uuid.Must(uuid.NewV7())
+	orderID := uuid.Must(uuid.NewV7())
+	query := &mockOrderQuery{
+		orders: map[uuid.UUID]appmodel.OrderDetails{
+			orderID: {OrderID: orderID, UserID: customerID},
+		},
+		history: map[uuid.UUID][]appmodel.OrderHistoryEvent{
+			orderID: {{EventID: 1, Type: model.OrderCreated{}.Type()}},
+		},
+	}
+	queryService := service.NewOrderQueryService(query)
+	asCustomer := func(userID uuid.UUID) context.Context {
+		return service.WithIdentity(context.Background(), service.Identity{UserID: userID, Roles: []string{service.RoleCustomer}})
+	}
+
+	t.Run("Customer reads own order and its history", func(t *testing.T) {
+		ctx := asCustomer(customerID)
+
+		order, err := queryService.GetOrder(ctx, orderID)
+		require.NoError(t, err)
+		require.Equal(t, orderID, order.OrderID)
+
+		history, err := queryService.GetOrderHistory(ctx, orderID)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+	})
+
+	t.Run("Order of other customer is not found", func(t *testing.T) {
+		ctx := asCustomer(uuid.Must(uuid.NewV7()))
+
+		_, err := queryService.GetOrder(ctx, orderID)
+		require.ErrorIs(t, err, model.ErrOrderNotFound)
+		_, err = queryService.GetOrderHistory(ctx, orderID)
+		require.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+
+	t.Run("Missing order is not found", func(t *testing.T) {
+		ctx := asCustomer(customerID)
+
+		_, err := queryService.GetOrder(ctx, uuid.Must(uuid.NewV7()))
+		require.ErrorIs(t, err, model.ErrOrderNotFound)
+		_, err = queryService.GetOrderHistory(ctx, uuid.Must(uuid.NewV7()))
+		require.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+}
+
+type mockOrderQuery struct {
+	orders  map[uuid.UUID]appmodel.OrderDetails
+	history map[uuid.UUID][]appmodel.OrderHistoryEvent
+}
+
+func (m *mockOrderQuery) GetOrder(_ context.Context, orderID uuid.UUID) (*appmodel.OrderDetails, error) {
+	order, ok := m.orders[orderID]
+	if !ok {
+		return nil, nil
+	}
+	return &order, nil
+}
+
+func (m *mockOrderQuery) GetOrderHistory(_ context.Context, orderID uuid.UUID) ([]appmodel.OrderHistoryEvent, error) {
+	return m.history[orderID], nil
+}
@@ -0,0 +1,301 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	infraamqp "order/pkg/infrastructure/amqp"
+)
+
+func TestOrderServiceCreateOrderAsync(t *testing.T) {
+	productID := uuid.Must(uuid.NewV7())
+	order := appmodel.Order{
+		UserID: uuid.Must(uuid.NewV7()),
+		Items: []appmodel.OrderItem{
+			{ProductID: productID, Quantity: 2},
+		},
+	}
+
+	t.Run("Publishes order created after commit", func(t *testing.T) {
+		uow := newMockUnitOfWork()
+		publisher := &mockEventPublisher{uow: uow}
+		orderService := service.NewOrderService(uow, &mockProductService{price: 10}, publisher, nil, noQuotas)
+
+		orderID, err := orderService.CreateOrderAsync(context.Background(), order)
+		require.NoError(t, err)
+
+		require.Len(t, publisher.events, 1)
+		require.Equal(t, orderID, publisher.events[0].OrderID)
+		require.Equal(t, order.UserID, publisher.events[0].UserID)
+		require.Equal(t, 20.0, publisher.events[0].TotalAmount)
+		require.True(t, publisher.publishedAfterCommit)
+		require.Len(t, uow.repo.store[orderID].Items, 2)
+	})
+
+	t.Run("Records order history with initiator", func(t *testing.T) {
+		uow := newMockUnitOfWork()
+		orderService := service.NewOrderService(uow, &mockProductService{price: 10}, &mockEventPublisher{uow: uow}, nil, noQuotas)
+
+		ctx := service.WithInitiator(context.Background(), service.Initiator{
+			Actor:  "support",
+			Source: "/Order.OrderInternalService/CreateOrderAsync",
+		})
+		orderID, err := orderService.CreateOrderAsync(ctx, order)
+		require.NoError(t, err)
+
+		records, err := uow.eventsRepo.FindByOrderID(ctx, orderID)
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, model.OrderCreated{}.Type(), records[0].Event.Type())
+		for _, record := range records {
+			require.Equal(t, "support", record.Actor)
+			require.Equal(t, "/Order.OrderInternalService/CreateOrderAsync", record.Source)
+		}
+	})
+
+	t.Run("Rejects order on behalf of another customer", func(t *testing.T) {
+		uow := newMockUnitOfWork()
+		publisher := &mockEventPublisher{uow: uow}
+		orderService := service.NewOrderService(uow, &mockProductService{price: 10}, publisher, nil, noQuotas)
+
+		ctx := service.WithIdentity(context.Background(), service.Identity{
+			UserID: uuid.Must(uuid.NewV7()),
+			Roles:  []string{service.RoleCustomer},
+		})
+		_, err := orderService.CreateOrderAsync(ctx, order)
+		require.ErrorIs(t, err, service.ErrPermissionDenied)
+		require.Empty(t, publisher.events)
+		require.Empty(t, uow.repo.store)
+	})
+
+	t.Run("Does not publish when commit fails", func(t *testing.T) {
+		uow := newMockUnitOfWork()
+		uow.commitErr = errors.New("commit failed")
+		publisher := &mockEventPublisher{uow: uow}
+		orderService := service.NewOrderService(uow, &mockProductService{price: 10}, publisher, nil, noQuotas)
+
+		_, err := orderService.CreateOrderAsync(context.Background(), order)
+		require.ErrorIs(t, err, uow.commitErr)
+		require.Empty(t, publisher.events)
+	})
+
+	t.Run("Does not publish when transaction fails", func(t *testing.T) {
+		uow := newMockUnitOfWork()
+		publisher := &mockEventPublisher{uow: uow}
+		productErr := errors.New("product not found")
+		orderService := service.NewOrderService(uow, &mockProductService{err: productErr}, publisher, nil, noQuotas)
+
+		_, err := orderService.CreateOrderAsync(context.Background(), order)
+		require.ErrorIs(t, err, productErr)
+		require.Empty(t, publisher.events)
+	})
+
+	t.Run("Publish error after commit doesn't fail created order", func(t *testing.T) {
+		uow := newMockUnitOfWork()
+		publisher := &mockEventPublisher{uow: uow, err: errors.New("broker unavailable")}
+		orderService := service.NewOrderService(uow, &mockProductService{price: 10}, publisher, nil, noQuotas)
+
+		orderID, err := orderService.CreateOrderAsync(context.Background(), order)
+		require.NoError(t, err)
+		require.NotNil(t, uow.repo.store[orderID])
+		require.Equal(t, []error{publisher.err}, uow.hookErrs)
+	})
+}
+
+// noQuotas has all limits disabled, so count query is never called
+var noQuotas = service.NewQuotaChecker(service.QuotaConfig{})
+
+var _ service.UnitOfWork = &mockUnitOfWork{}
+
+func newMockUnitOfWork() *mockUnitOfWork {
+	return &mockUnitOfWork{
+		repo:       &mockOrderRepository{store: map[uuid.UUID]*model.Order{}},
+		eventsRepo: &mockOrderEventRepository{},
+	}
+}
+
+// mockUnitOfWork runs callback without real transaction and simulates commit result with commitErr
+type mockUnitOfWork struct {
+	repo       *mockOrderRepository
+	eventsRepo *mockOrderEventRepository
+	countQuery service.OrderCountQuery
+	commitErr  error
+
+	active    bool
+	committed bool
+	hooks     []service.PostCommitHook
+	hookErrs  []error
+}
+
+func (m *mockUnitOfWork) Execute(ctx context.Context, f func(provider service.RepositoryProvider) error) error {
+	m.active = true
+	m.committed = false
+	m.hooks = nil
+	defer func() {
+		m.active = false
+	}()
+
+	err := f(&mockRepositoryProvider{repo: m.repo, eventsRepo: m.eventsRepo, countQuery: m.countQuery})
+	if err != nil {
+		return err
+	}
+	if m.commitErr != nil {
+		return m.commitErr
+	}
+	m.committed = true
+
+	for _, hook := range m.hooks {
+		if hookErr := hook(ctx); hookErr != nil {
+			m.hookErrs = append(m.hookErrs, hookErr)
+		}
+	}
+	return nil
+}
+
+func (m *mockUnitOfWork) AfterCommit(_ context.Context, hook service.PostCommitHook) error {
+	if !m.active {
+		return service.ErrNoActiveUnitOfWork
+	}
+	m.hooks = append(m.hooks, hook)
+	return nil
+}
+
+type mockRepositoryProvider struct {
+	repo       *mockOrderRepository
+	eventsRepo *mockOrderEventRepository
+	countQuery service.OrderCountQuery
+}
+
+func (m *mockRepositoryProvider) OrderRepository() model.OrderRepository {
+	return m.repo
+}
+
+func (m *mockRepositoryProvider) OrderEventRepository() model.OrderEventRepository {
+	return m.eventsRepo
+}
+
+func (m *mockRepositoryProvider) OrderCountQuery() service.OrderCountQuery {
+	return m.countQuery
+}
+
+func (m *mockRepositoryProvider) OrderQuery() service.OrderQuery {
+	return &mockRepositoryOrderQuery{repo: m.repo, eventsRepo: m.eventsRepo}
+}
+
+// mockRepositoryOrderQuery reads orders stored by mock repositories
+type mockRepositoryOrderQuery struct {
+	repo       *mockOrderRepository
+	eventsRepo *mockOrderEventRepository
+}
+
+func (m *mockRepositoryOrderQuery) GetOrder(_ context.Context, orderID uuid.UUID) (*appmodel.OrderDetails, error) {
+	order, ok := m.repo.store[orderID]
+	if !ok {
+		return nil, nil
+	}
+	details := &appmodel.OrderDetails{
+		OrderID:    order.ID,
+		UserID:     order.CustomerID,
+		Status:     strconv.Itoa(int(order.Status)),
+		TotalPrice: order.TotalPrice(),
+	}
+	for _, item := range order.Items {
+		details.Items = append(details.Items, appmodel.OrderDetailsItem{ProductID: item.ProductID, Quantity: 1, Price: item.Price})
+	}
+	return details, nil
+}
+
+func (m *mockRepositoryOrderQuery) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]appmodel.OrderHistoryEvent, error) {
+	records, err := m.eventsRepo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]appmodel.OrderHistoryEvent, 0, len(records))
+	for _, record := range records {
+		events = append(events, appmodel.OrderHistoryEvent{EventID: record.ID, Type: record.Event.Type()})
+	}
+	return events, nil
+}
+
+var _ model.OrderRepository = &mockOrderRepository{}
+
+type mockOrderRepository struct {
+	store map[uuid.UUID]*model.Order
+}
+
+func (m *mockOrderRepository) NextID(_ context.Context) (uuid.UUID, error) {
+	return uuid.NewV7()
+}
+
+func (m *mockOrderRepository) Store(_ context.Context, order *model.Order) error {
+	m.store[order.ID] = order
+	return nil
+}
+
+func (m *mockOrderRepository) Find(_ context.Context, id uuid.UUID) (*model.Order, error) {
+	if order, ok := m.store[id]; ok && order.DeletedAt == nil {
+		return order, nil
+	}
+	return nil, model.ErrOrderNotFound
+}
+
+func (m *mockOrderRepository) Delete(_ context.Context, id uuid.UUID) error {
+	delete(m.store, id)
+	return nil
+}
+
+var _ model.OrderEventRepository = &mockOrderEventRepository{}
+
+type mockOrderEventRepository struct {
+	records []model.OrderEventRecord
+}
+
+func (m *mockOrderEventRepository) Append(_ context.Context, record *model.OrderEventRecord) error {
+	record.ID = int64(len(m.records) + 1)
+	m.records = append(m.records, *record)
+	return nil
+}
+
+func (m *mockOrderEventRepository) FindByOrderID(_ context.Context, orderID uuid.UUID) ([]model.OrderEventRecord, error) {
+	var records []model.OrderEventRecord
+	for _, record := range m.records {
+		if record.OrderID == orderID {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+type mockProductService struct {
+	price float64
+	err   error
+}
+
+func (m *mockProductService) GetPrice(_ context.Context, _ uuid.UUID) (float64, error) {
+	return m.price, m.err
+}
+
+type mockEventPublisher struct {
+	uow *mockUnitOfWork
+	err error
+
+	events               []infraamqp.OrderCreatedEvent
+	publishedAfterCommit bool
+}
+
+func (m *mockEventPublisher) PublishOrderCreated(_ context.Context, event infraamqp.OrderCreatedEvent) error {
+	m.publishedAfterCommit = m.uow.committed
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, event)
+	return nil
+}
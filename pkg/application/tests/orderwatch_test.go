@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+func TestOrderWatchService(t *testing.T) {
+	ctx := context.Background()
+
+	newWatchedOrder := func(t *testing.T, uow service.UnitOfWork) uuid.UUID {
+		t.Helper()
+		orderID := uuid.New()
+		require.NoError(t, uow.Execute(ctx, func(provider service.RepositoryProvider) error {
+			err := provider.OrderRepository().Store(ctx, &model.Order{ID: orderID, CustomerID: uuid.New()})
+			if err != nil {
+				return err
+			}
+			return appendEvent(ctx, provider, model.OrderCreated{OrderID: orderID})
+		}))
+		return orderID
+	}
+
+	t.Run("New watch starts with snapshot", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		uow := service.NewBroadcastingUnitOfWork(newMockUnitOfWork(), broker)
+		orderID := newWatchedOrder(t, uow)
+
+		watch, err := service.NewOrderWatchService(uow, broker).WatchOrder(ctx, orderID, 0)
+		require.NoError(t, err)
+		defer watch.Close()
+
+		require.NotNil(t, watch.Snapshot)
+		require.Equal(t, orderID, watch.Snapshot.OrderID)
+		require.Empty(t, watch.Missed)
+		require.EqualValues(t, 1, watch.LastEventID)
+	})
+
+	t.Run("Resumed watch gets missed events instead of snapshot", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		uow := service.NewBroadcastingUnitOfWork(newMockUnitOfWork(), broker)
+		orderID := newWatchedOrder(t, uow)
+		require.NoError(t, uow.Execute(ctx, func(provider service.RepositoryProvider) error {
+			return appendEvent(ctx, provider, model.OrderStatusChanged{OrderID: orderID, Status: model.Pending})
+		}))
+
+		watch, err := service.NewOrderWatchService(uow, broker).WatchOrder(ctx, orderID, 1)
+		require.NoError(t, err)
+		defer watch.Close()
+
+		require.Nil(t, watch.Snapshot)
+		require.Len(t, watch.Missed, 1)
+		require.EqualValues(t, 2, watch.Missed[0].ID)
+		require.EqualValues(t, 2, watch.LastEventID)
+	})
+
+	t.Run("Committed events are delivered to watcher", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		uow := service.NewBroadcastingUnitOfWork(newMockUnitOfWork(), broker)
+		orderID := newWatchedOrder(t, uow)
+
+		watch, err := service.NewOrderWatchService(uow, broker).WatchOrder(ctx, orderID, 0)
+		require.NoError(t, err)
+		defer watch.Close()
+
+		require.NoError(t, uow.Execute(ctx, func(provider service.RepositoryProvider) error {
+			return appendEvent(ctx, provider, model.OrderDeleted{OrderID: orderID})
+		}))
+
+		record := <-watch.Events()
+		require.EqualValues(t, 2, record.ID)
+		require.Equal(t, model.OrderDeleted{OrderID: orderID}, record.Event)
+
+		records, err := watch.CatchUp(ctx, record)
+		require.NoError(t, err)
+		require.Equal(t, []model.OrderEventRecord{record}, records)
+		require.EqualValues(t, 2, watch.LastEventID)
+	})
+
+	t.Run("Event broadcast before event with lower ID is sent after it", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		mockUoW := newMockUnitOfWork()
+		uow := service.NewBroadcastingUnitOfWork(mockUoW, broker)
+		orderID := newWatchedOrder(t, uow)
+
+		watch, err := service.NewOrderWatchService(uow, broker).WatchOrder(ctx, orderID, 0)
+		require.NoError(t, err)
+		defer watch.Close()
+
+		// both events are committed, but hook of the second one runs first
+		require.NoError(t, mockUoW.Execute(ctx, func(provider service.RepositoryProvider) error {
+			err := appendEvent(ctx, provider, model.OrderStatusChanged{OrderID: orderID, Status: model.Pending})
+			if err != nil {
+				return err
+			}
+			return appendEvent(ctx, provider, model.OrderDeleted{OrderID: orderID})
+		}))
+		history := mockUoW.eventsRepo.records
+		broker.Publish(history[2])
+		broker.Publish(history[1])
+
+		records, err := watch.CatchUp(ctx, <-watch.Events())
+		require.NoError(t, err)
+		require.Equal(t, history[1:], records)
+		require.EqualValues(t, 3, watch.LastEventID)
+
+		records, err = watch.CatchUp(ctx, <-watch.Events())
+		require.NoError(t, err)
+		require.Empty(t, records)
+	})
+
+	t.Run("Events of rolled back transaction are not delivered", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		mockUoW := newMockUnitOfWork()
+		uow := service.NewBroadcastingUnitOfWork(mockUoW, broker)
+		orderID := newWatchedOrder(t, uow)
+
+		watch, err := service.NewOrderWatchService(uow, broker).WatchOrder(ctx, orderID, 0)
+		require.NoError(t, err)
+		defer watch.Close()
+
+		mockUoW.commitErr = context.DeadlineExceeded
+		require.Error(t, uow.Execute(ctx, func(provider service.RepositoryProvider) error {
+			return appendEvent(ctx, provider, model.OrderDeleted{OrderID: orderID})
+		}))
+
+		require.Empty(t, watch.Events())
+	})
+
+	t.Run("Lagging watcher is disconnected", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		uow := service.NewBroadcastingUnitOfWork(newMockUnitOfWork(), broker)
+		orderID := newWatchedOrder(t, uow)
+
+		watch, err := service.NewOrderWatchService(uow, broker).WatchOrder(ctx, orderID, 0)
+		require.NoError(t, err)
+		defer watch.Close()
+
+		for i := 0; i < 100; i++ {
+			broker.Publish(model.OrderEventRecord{ID: int64(i + 2), OrderID: orderID, Event: model.OrderDeleted{OrderID: orderID}})
+		}
+
+		received := 0
+		for range watch.Events() {
+			received++
+		}
+		require.Less(t, received, 100)
+	})
+
+	t.Run("Watch of missing order fails", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		uow := service.NewBroadcastingUnitOfWork(newMockUnitOfWork(), broker)
+
+		_, err := service.NewOrderWatchService(uow, broker).WatchOrder(ctx, uuid.New(), 0)
+		require.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+
+	t.Run("Watch of other customer order is not found", func(t *testing.T) {
+		broker := service.NewOrderEventBroker()
+		uow := service.NewBroadcastingUnitOfWork(newMockUnitOfWork(), broker)
+		orderID := newWatchedOrder(t, uow)
+		otherCustomerCtx := service.WithIdentity(ctx, service.Identity{UserID: uuid.New(), Roles: []string{service.RoleCustomer}})
+
+		_, err := service.NewOrderWatchService(uow, broker).WatchOrder(otherCustomerCtx, orderID, 0)
+		require.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+}
+
+func appendEvent(ctx context.Context, provider service.RepositoryProvider, event model.OrderEvent) error {
+	return provider.OrderEventRepository().Append(ctx, &model.OrderEventRecord{
+		OrderID: event.AggregateID(),
+		Event:   event,
+	})
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/application/service"
+)
+
+func TestQuotaChecker(t *testing.T) {
+	ctx := context.Background()
+	customerID := uuid.Must(uuid.NewV7())
+
+	t.Run("Open orders quota", func(t *testing.T) {
+		checker := service.NewQuotaChecker(service.QuotaConfig{MaxOpenOrders: 3})
+
+		err := checker.CheckCreateOrder(ctx, &mockOrderCountQuery{openOrders: 3}, customerID)
+		require.ErrorIs(t, err, service.ErrQuotaExceeded)
+		var quotaErr *service.QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Zero(t, quotaErr.RetryAfter)
+
+		require.NoError(t, checker.CheckCreateOrder(ctx, &mockOrderCountQuery{openOrders: 2}, customerID))
+	})
+
+	t.Run("Customer orders are locked before counting", func(t *testing.T) {
+		query := &mockOrderCountQuery{}
+		require.NoError(t, service.NewQuotaChecker(service.QuotaConfig{MaxOpenOrders: 3}).CheckCreateOrder(ctx, query, customerID))
+		require.True(t, query.lockedBeforeCount)
+
+		query = &mockOrderCountQuery{}
+		require.NoError(t, service.NewQuotaChecker(service.QuotaConfig{}).CheckCreateOrder(ctx, query, customerID))
+		require.False(t, query.locked, "disabled quotas don't lock")
+	})
+
+	t.Run("Hourly quota tells when the oldest order leaves window", func(t *testing.T) {
+		query := &mockOrderCountQuery{recentOrders: 5, oldest: time.Now().Add(-50 * time.Minute)}
+		checker := service.NewQuotaChecker(service.QuotaConfig{MaxOrdersPerHour: 5})
+
+		err := checker.CheckCreateOrder(ctx, query, customerID)
+		var quotaErr *service.QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.InDelta(t, 10*time.Minute, quotaErr.RetryAfter, float64(time.Second))
+		require.WithinDuration(t, time.Now().Add(-time.Hour), query.since, time.Second)
+	})
+
+	t.Run("Order service rejects order over quota", func(t *testing.T) {
+		uow := newMockUnitOfWork()
+		uow.countQuery = &mockOrderCountQuery{openOrders: 1}
+		publisher := &mockEventPublisher{uow: uow}
+		checker := service.NewQuotaChecker(service.QuotaConfig{MaxOpenOrders: 1})
+		// workflow starter is nil, so order over quota must be rejected before workflow is started
+		orderService := service.NewOrderService(uow, &mockProductService{price: 10}, publisher, nil, checker)
+
+		_, err := orderService.CreateOrderAsync(ctx, appmodel.Order{UserID: customerID})
+		require.ErrorIs(t, err, service.ErrQuotaExceeded)
+		require.Empty(t, uow.repo.store)
+
+		_, err = orderService.CreateOrder(ctx, appmodel.Order{UserID: customerID})
+		require.ErrorIs(t, err, service.ErrQuotaExceeded)
+	})
+}
+
+type mockOrderCountQuery struct {
+	openOrders   int
+	recentOrders int
+	oldest       time.Time
+	since        time.Time
+
+	locked            bool
+	lockedBeforeCount bool
+}
+
+func (m *mockOrderCountQuery) LockCustomerOrders(_ context.Context, _ uuid.UUID) error {
+	m.locked = true
+	return nil
+}
+
+func (m *mockOrderCountQuery) CountOpenOrders(_ context.Context, _ uuid.UUID) (int, error) {
+	m.lockedBeforeCount = m.locked
+	return m.openOrders, nil
+}
+
+func (m *mockOrderCountQuery) CountOrdersSince(_ context.Context, _ uuid.UUID, since time.Time) (int, time.Time, error) {
+	m.lockedBeforeCount = m.locked
+	m.since = since
+	return m.recentOrders, m.oldest, nil
+}
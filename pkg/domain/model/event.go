@@ -1,40 +1,86 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"context"
+	"time"
 
-type OrderCreated struct {
+	"github.com/google/uuid"
+)
+
+type Event interface {
+	Type() string
+}
+
+// OrderEvent is an event related to a single order aggregate
+type OrderEvent interface {
+	Event
+	AggregateID() uuid.UUID
+}
+
+// OrderEventRecord is an order event stored in order history
+type OrderEventRecord struct {
+	ID         int64
 	OrderID    uuid.UUID
-	CustomerID uuid.UUID
+	Event      OrderEvent
+	Actor      string
+	Source     string
+	OccurredAt time.Time
+}
+
+type OrderEventRepository interface {
+	Append(ctx context.Context, record *OrderEventRecord) error
+	FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]OrderEventRecord, error)
+}
+
+type OrderCreated struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
 }
 
 func (e OrderCreated) Type() string {
 	return "OrderCreated"
 }
 
+func (e OrderCreated) AggregateID() uuid.UUID {
+	return e.OrderID
+}
+
 type OrderItemChanged struct {
-	OrderID      uuid.UUID
-	AddedItems   []uuid.UUID
-	RemovedItems []uuid.UUID
+	OrderID      uuid.UUID   `json:"order_id"`
+	AddedItems   []Item      `json:"added_items,omitempty"`
+	RemovedItems []uuid.UUID `json:"removed_items,omitempty"`
 }
 
 func (e OrderItemChanged) Type() string {
 	return "OrderItemChanged"
 }
 
+func (e OrderItemChanged) AggregateID() uuid.UUID {
+	return e.OrderID
+}
+
 type OrderStatusChanged struct {
-	OrderID        uuid.UUID
-	Status         OrderStatus
-	PreviousStatus OrderStatus
+	OrderID        uuid.UUID   `json:"order_id"`
+	Status         OrderStatus `json:"status"`
+	PreviousStatus OrderStatus `json:"previous_status"`
 }
 
 func (e OrderStatusChanged) Type() string {
 	return "OrderStatusChanged"
 }
 
+func (e OrderStatusChanged) AggregateID() uuid.UUID {
+	return e.OrderID
+}
+
 type OrderDeleted struct {
-	OrderID uuid.UUID
+	OrderID uuid.UUID `json:"order_id"`
 }
 
 func (e OrderDeleted) Type() string {
 	return "OrderDeleted"
 }
+
+func (e OrderDeleted) AggregateID() uuid.UUID {
+	return e.OrderID
+}
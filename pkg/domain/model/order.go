@@ -1,7 +1,10 @@
 package model
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,17 +29,69 @@ type Order struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 	DeletedAt  *time.Time
+
+	// persistedItems is a snapshot of items as they are in storage, nil if order was not loaded from storage
+	persistedItems map[uuid.UUID]Item
+}
+
+// TotalPrice sums prices of order items
+func (o *Order) TotalPrice() float64 {
+	var total float64
+	for _, item := range o.Items {
+		total += item.Price
+	}
+	return total
+}
+
+// ItemChanges describes items changed since order was loaded from or stored to storage
+type ItemChanges struct {
+	Upserted []Item
+	Removed  []uuid.UUID
+}
+
+// ItemChanges returns changes of items since last MarkItemsPersisted call,
+// false is returned when items are not tracked yet and whole list must be persisted
+func (o *Order) ItemChanges() (ItemChanges, bool) {
+	if o.persistedItems == nil {
+		return ItemChanges{}, false
+	}
+
+	var changes ItemChanges
+	current := make(map[uuid.UUID]struct{}, len(o.Items))
+	for _, item := range o.Items {
+		current[item.ID] = struct{}{}
+		if persisted, ok := o.persistedItems[item.ID]; !ok || persisted != item {
+			changes.Upserted = append(changes.Upserted, item)
+		}
+	}
+	for itemID := range o.persistedItems {
+		if _, ok := current[itemID]; !ok {
+			changes.Removed = append(changes.Removed, itemID)
+		}
+	}
+	slices.SortFunc(changes.Removed, func(l, r uuid.UUID) int {
+		return bytes.Compare(l[:], r[:])
+	})
+	return changes, true
+}
+
+// MarkItemsPersisted takes snapshot of current items to track further changes
+func (o *Order) MarkItemsPersisted() {
+	o.persistedItems = make(map[uuid.UUID]Item, len(o.Items))
+	for _, item := range o.Items {
+		o.persistedItems[item.ID] = item
+	}
 }
 
 type Item struct {
-	ID        uuid.UUID
-	ProductID uuid.UUID
-	Price     float64
+	ID        uuid.UUID `json:"item_id"`
+	ProductID uuid.UUID `json:"product_id"`
+	Price     float64   `json:"price"`
 }
 
 type OrderRepository interface {
-	NextID() (uuid.UUID, error)
-	Store(order *Order) error
-	Find(id uuid.UUID) (*Order, error)
-	Delete(id uuid.UUID) error
+	NextID(ctx context.Context) (uuid.UUID, error)
+	Store(ctx context.Context, order *Order) error
+	Find(ctx context.Context, id uuid.UUID) (*Order, error)
+	Delete(ctx context.Context, id uuid.UUID) error
 }
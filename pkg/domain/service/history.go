@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"order/pkg/domain/model"
+)
+
+var ErrInvalidEventStream = errors.New("invalid order event stream")
+
+// RebuildOrder restores order state by applying its recorded events in order of occurrence
+func RebuildOrder(records []model.OrderEventRecord) (*model.Order, error) {
+	if len(records) == 0 {
+		return nil, model.ErrOrderNotFound
+	}
+
+	var order *model.Order
+	for _, record := range records {
+		if order == nil {
+			created, ok := record.Event.(model.OrderCreated)
+			if !ok {
+				return nil, fmt.Errorf("%w: stream must start with %s, got %s", ErrInvalidEventStream, model.OrderCreated{}.Type(), record.Event.Type())
+			}
+			order = &model.Order{
+				ID:         created.OrderID,
+				CustomerID: created.CustomerID,
+				Status:     model.Open,
+				CreatedAt:  record.OccurredAt,
+				UpdatedAt:  record.OccurredAt,
+			}
+			continue
+		}
+
+		if record.OrderID != order.ID {
+			return nil, fmt.Errorf("%w: event %d belongs to order %s", ErrInvalidEventStream, record.ID, record.OrderID)
+		}
+
+		switch e := record.Event.(type) {
+		case model.OrderItemChanged:
+			order.Items = append(order.Items, e.AddedItems...)
+			for _, itemID := range e.RemovedItems {
+				order.Items = removeItem(order.Items, itemID)
+			}
+		case model.OrderStatusChanged:
+			order.Status = e.Status
+		case model.OrderDeleted:
+			deletedAt := record.OccurredAt
+			order.DeletedAt = &deletedAt
+		default:
+			return nil, fmt.Errorf("%w: unexpected event %s", ErrInvalidEventStream, record.Event.Type())
+		}
+		order.UpdatedAt = record.OccurredAt
+	}
+	return order, nil
+}
+
+func removeItem(items []model.Item, itemID uuid.UUID) []model.Item {
+	for i, item := range items {
+		if item.ID == itemID {
+			return append(items[:i], items[i+1:]...)
+		}
+	}
+	return items
+}
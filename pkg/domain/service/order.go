@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -13,21 +14,19 @@ var (
 	ErrInvalidOrderStatus = errors.New("invalid order status")
 )
 
-type Event interface {
-	Type() string
-}
+type Event = model.Event
 
 type EventDispatcher interface {
-	Dispatch(event Event) error
+	Dispatch(ctx context.Context, event Event) error
 }
 
 type Order interface {
-	CreateOrder(customerID uuid.UUID) (uuid.UUID, error)
-	DeleteOrder(orderID uuid.UUID) error
-	SetStatus(orderID uuid.UUID, status model.OrderStatus) error
+	CreateOrder(ctx context.Context, customerID uuid.UUID) (uuid.UUID, error)
+	DeleteOrder(ctx context.Context, orderID uuid.UUID) error
+	SetStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error
 
-	AddItem(orderID uuid.UUID, productID uuid.UUID, price float64) (uuid.UUID, error)
-	DeleteItem(orderID uuid.UUID, itemID uuid.UUID) error
+	AddItem(ctx context.Context, orderID uuid.UUID, productID uuid.UUID, price float64) (uuid.UUID, error)
+	DeleteItem(ctx context.Context, orderID uuid.UUID, itemID uuid.UUID) error
 }
 
 func NewOrderService(repo model.OrderRepository, dispatcher EventDispatcher) Order {
@@ -42,14 +41,14 @@ type orderService struct {
 	dispatcher EventDispatcher
 }
 
-func (o orderService) CreateOrder(customerID uuid.UUID) (uuid.UUID, error) {
-	orderID, err := o.repo.NextID()
+func (o orderService) CreateOrder(ctx context.Context, customerID uuid.UUID) (uuid.UUID, error) {
+	orderID, err := o.repo.NextID(ctx)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
 	currentTime := time.Now()
-	err = o.repo.Store(&model.Order{
+	err = o.repo.Store(ctx, &model.Order{
 		ID:         orderID,
 		CustomerID: customerID,
 		Status:     model.Open,
@@ -60,14 +59,14 @@ func (o orderService) CreateOrder(customerID uuid.UUID) (uuid.UUID, error) {
 		return uuid.Nil, err
 	}
 
-	return orderID, o.dispatcher.Dispatch(model.OrderCreated{
+	return orderID, o.dispatcher.Dispatch(ctx, model.OrderCreated{
 		OrderID:    orderID,
 		CustomerID: customerID,
 	})
 }
 
-func (o orderService) DeleteOrder(orderID uuid.UUID) error {
-	order, err := o.repo.Find(orderID)
+func (o orderService) DeleteOrder(ctx context.Context, orderID uuid.UUID) error {
+	order, err := o.repo.Find(ctx, orderID)
 	if err != nil {
 		return err
 	}
@@ -76,18 +75,18 @@ func (o orderService) DeleteOrder(orderID uuid.UUID) error {
 	order.DeletedAt = &currentTime
 	order.UpdatedAt = currentTime
 
-	err = o.repo.Store(order)
+	err = o.repo.Store(ctx, order)
 	if err != nil {
 		return err
 	}
 
-	return o.dispatcher.Dispatch(model.OrderDeleted{
+	return o.dispatcher.Dispatch(ctx, model.OrderDeleted{
 		OrderID: orderID,
 	})
 }
 
-func (o orderService) SetStatus(orderID uuid.UUID, status model.OrderStatus) error {
-	order, err := o.repo.Find(orderID)
+func (o orderService) SetStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error {
+	order, err := o.repo.Find(ctx, orderID)
 	if err != nil {
 		return err
 	}
@@ -100,20 +99,20 @@ func (o orderService) SetStatus(orderID uuid.UUID, status model.OrderStatus) err
 	order.Status = status
 	order.UpdatedAt = time.Now()
 
-	err = o.repo.Store(order)
+	err = o.repo.Store(ctx, order)
 	if err != nil {
 		return err
 	}
 
-	return o.dispatcher.Dispatch(model.OrderStatusChanged{
+	return o.dispatcher.Dispatch(ctx, model.OrderStatusChanged{
 		OrderID:        orderID,
 		Status:         status,
 		PreviousStatus: previousStatus,
 	})
 }
 
-func (o orderService) AddItem(orderID uuid.UUID, productID uuid.UUID, price float64) (uuid.UUID, error) {
-	order, err := o.repo.Find(orderID)
+func (o orderService) AddItem(ctx context.Context, orderID uuid.UUID, productID uuid.UUID, price float64) (uuid.UUID, error) {
+	order, err := o.repo.Find(ctx, orderID)
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -122,28 +121,29 @@ func (o orderService) AddItem(orderID uuid.UUID, productID uuid.UUID, price floa
 		return uuid.Nil, ErrInvalidOrderStatus
 	}
 
-	itemID, err := o.repo.NextID()
+	itemID, err := o.repo.NextID(ctx)
 	if err != nil {
 		return uuid.Nil, err
 	}
-	order.Items = append(order.Items, model.Item{
+	item := model.Item{
 		ID:        itemID,
 		ProductID: productID,
 		Price:     price,
-	})
-	err = o.repo.Store(order)
+	}
+	order.Items = append(order.Items, item)
+	err = o.repo.Store(ctx, order)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	return itemID, o.dispatcher.Dispatch(model.OrderItemChanged{
+	return itemID, o.dispatcher.Dispatch(ctx, model.OrderItemChanged{
 		OrderID:    orderID,
-		AddedItems: []uuid.UUID{itemID},
+		AddedItems: []model.Item{item},
 	})
 }
 
-func (o orderService) DeleteItem(orderID uuid.UUID, itemID uuid.UUID) error {
-	order, err := o.repo.Find(orderID)
+func (o orderService) DeleteItem(ctx context.Context, orderID uuid.UUID, itemID uuid.UUID) error {
+	order, err := o.repo.Find(ctx, orderID)
 	if err != nil {
 		return err
 	}
@@ -167,12 +167,12 @@ func (o orderService) DeleteItem(orderID uuid.UUID, itemID uuid.UUID) error {
 	order.Items = append(order.Items[:itemIndex], order.Items[itemIndex+1:]...)
 	order.UpdatedAt = time.Now()
 
-	err = o.repo.Store(order)
+	err = o.repo.Store(ctx, order)
 	if err != nil {
 		return err
 	}
 
-	return o.dispatcher.Dispatch(model.OrderItemChanged{
+	return o.dispatcher.Dispatch(ctx, model.OrderItemChanged{
 		OrderID:      orderID,
 		RemovedItems: []uuid.UUID{itemID},
 	})
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+	"order/pkg/domain/service"
+)
+
+func TestRebuildOrder(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+	orderService := service.NewOrderService(repo, eventDispatcher)
+
+	customerID := uuid.Must(uuid.NewV7())
+
+	t.Run("Rebuilt order matches stored order", func(t *testing.T) {
+		eventDispatcher.events = []service.Event{}
+		orderID, err := orderService.CreateOrder(ctx, customerID)
+		require.NoError(t, err)
+		firstItemID, err := orderService.AddItem(ctx, orderID, uuid.Must(uuid.NewV7()), 10)
+		require.NoError(t, err)
+		_, err = orderService.AddItem(ctx, orderID, uuid.Must(uuid.NewV7()), 20)
+		require.NoError(t, err)
+		require.NoError(t, orderService.DeleteItem(ctx, orderID, firstItemID))
+		require.NoError(t, orderService.SetStatus(ctx, orderID, model.Pending))
+
+		order, err := service.RebuildOrder(toRecords(eventDispatcher.events))
+		require.NoError(t, err)
+
+		stored := repo.store[orderID]
+		require.Equal(t, stored.ID, order.ID)
+		require.Equal(t, stored.CustomerID, order.CustomerID)
+		require.Equal(t, stored.Status, order.Status)
+		require.Equal(t, stored.Items, order.Items)
+		require.Nil(t, order.DeletedAt)
+	})
+
+	t.Run("Rebuilt deleted order", func(t *testing.T) {
+		eventDispatcher.events = []service.Event{}
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
+		require.NoError(t, orderService.DeleteOrder(ctx, orderID))
+
+		order, err := service.RebuildOrder(toRecords(eventDispatcher.events))
+		require.NoError(t, err)
+		require.NotNil(t, order.DeletedAt)
+	})
+
+	t.Run("Stream must start with order created", func(t *testing.T) {
+		orderID := uuid.Must(uuid.NewV7())
+		_, err := service.RebuildOrder(toRecords([]service.Event{
+			model.OrderStatusChanged{OrderID: orderID, Status: model.Paid},
+		}))
+		require.ErrorIs(t, err, service.ErrInvalidEventStream)
+	})
+
+	t.Run("Empty stream", func(t *testing.T) {
+		_, err := service.RebuildOrder(nil)
+		require.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+}
+
+func toRecords(events []service.Event) []model.OrderEventRecord {
+	records := make([]model.OrderEventRecord, 0, len(events))
+	occurredAt := time.Now()
+	for i, event := range events {
+		orderEvent := event.(model.OrderEvent)
+		records = append(records, model.OrderEventRecord{
+			ID:         int64(i + 1),
+			OrderID:    orderEvent.AggregateID(),
+			Event:      orderEvent,
+			OccurredAt: occurredAt.Add(time.Duration(i) * time.Second),
+		})
+	}
+	return records
+}
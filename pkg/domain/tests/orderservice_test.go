@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 )
 
 func TestOrderService(t *testing.T) {
+	ctx := context.Background()
 	repo := &mockOrderRepository{
 		store: map[uuid.UUID]*model.Order{},
 	}
@@ -23,7 +25,7 @@ func TestOrderService(t *testing.T) {
 
 	t.Run("Create order", func(t *testing.T) {
 		eventDispatcher.events = []service.Event{}
-		orderID, err := orderService.CreateOrder(customerID)
+		orderID, err := orderService.CreateOrder(ctx, customerID)
 		require.NoError(t, err)
 
 		require.NotNil(t, repo.store[orderID])
@@ -34,11 +36,11 @@ func TestOrderService(t *testing.T) {
 
 	t.Run("Add item to order", func(t *testing.T) {
 		eventDispatcher.events = []service.Event{}
-		orderID, _ := orderService.CreateOrder(customerID)
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
 		eventDispatcher.events = []service.Event{}
 
 		productID := uuid.Must(uuid.NewV7())
-		itemID, err := orderService.AddItem(orderID, productID, 99.99)
+		itemID, err := orderService.AddItem(ctx, orderID, productID, 99.99)
 		require.NoError(t, err)
 
 		order := repo.store[orderID]
@@ -52,12 +54,12 @@ func TestOrderService(t *testing.T) {
 
 	t.Run("Delete item from order", func(t *testing.T) {
 		eventDispatcher.events = []service.Event{} 
-		orderID, _ := orderService.CreateOrder(customerID)
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
 		productID := uuid.Must(uuid.NewV7())
-		itemID, _ := orderService.AddItem(orderID, productID, 50.0)
+		itemID, _ := orderService.AddItem(ctx, orderID, productID, 50.0)
 		eventDispatcher.events = []service.Event{}
 
-		err := orderService.DeleteItem(orderID, itemID)
+		err := orderService.DeleteItem(ctx, orderID, itemID)
 		require.NoError(t, err)
 
 		order := repo.store[orderID]
@@ -67,22 +69,22 @@ func TestOrderService(t *testing.T) {
 	})
 
 	t.Run("Cannot add item to non-open order", func(t *testing.T) {
-		orderID, _ := orderService.CreateOrder(customerID)
-		orderService.SetStatus(orderID, model.Pending)
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
+		orderService.SetStatus(ctx, orderID, model.Pending)
 		eventDispatcher.events = []service.Event{}
 
 		productID := uuid.Must(uuid.NewV7())
-		_, err := orderService.AddItem(orderID, productID, 100.0)
+		_, err := orderService.AddItem(ctx, orderID, productID, 100.0)
 		require.Error(t, err)
 		require.Equal(t, service.ErrInvalidOrderStatus, err)
 	})
 
 	t.Run("Change order status", func(t *testing.T) {
 		eventDispatcher.events = []service.Event{} 
-		orderID, _ := orderService.CreateOrder(customerID)
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
 		eventDispatcher.events = []service.Event{} 
 
-		err := orderService.SetStatus(orderID, model.Pending)
+		err := orderService.SetStatus(ctx, orderID, model.Pending)
 		require.NoError(t, err)
 
 		order := repo.store[orderID]
@@ -97,10 +99,10 @@ func TestOrderService(t *testing.T) {
 
 	t.Run("Delete order", func(t *testing.T) {
 		eventDispatcher.events = []service.Event{} 
-		orderID, _ := orderService.CreateOrder(customerID)
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
 		eventDispatcher.events = []service.Event{} 
 
-		err := orderService.DeleteOrder(orderID)
+		err := orderService.DeleteOrder(ctx, orderID)
 		require.NoError(t, err)
 
 		order := repo.store[orderID]
@@ -108,28 +110,28 @@ func TestOrderService(t *testing.T) {
 		require.Len(t, eventDispatcher.events, 1)
 		require.Equal(t, model.OrderDeleted{}.Type(), eventDispatcher.events[0].Type())
 
-		_, err = orderService.AddItem(orderID, uuid.Must(uuid.NewV7()), 100.0)
+		_, err = orderService.AddItem(ctx, orderID, uuid.Must(uuid.NewV7()), 100.0)
 		require.Error(t, err)
 		require.Equal(t, model.ErrOrderNotFound, err)
 	})
 
 	t.Run("Cannot delete item from non-open order", func(t *testing.T) {
-		orderID, _ := orderService.CreateOrder(customerID)
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
 		productID := uuid.Must(uuid.NewV7())
-		itemID, _ := orderService.AddItem(orderID, productID, 50.0)
-		orderService.SetStatus(orderID, model.Paid)
+		itemID, _ := orderService.AddItem(ctx, orderID, productID, 50.0)
+		orderService.SetStatus(ctx, orderID, model.Paid)
 
-		err := orderService.DeleteItem(orderID, itemID)
+		err := orderService.DeleteItem(ctx, orderID, itemID)
 		require.Error(t, err)
 		require.Equal(t, service.ErrInvalidOrderStatus, err)
 	})
 
 	t.Run("Set same status does not dispatch event", func(t *testing.T) {
 		eventDispatcher.events = []service.Event{} 
-		orderID, _ := orderService.CreateOrder(customerID)
+		orderID, _ := orderService.CreateOrder(ctx, customerID)
 		eventDispatcher.events = []service.Event{} 
 
-		err := orderService.SetStatus(orderID, model.Open)
+		err := orderService.SetStatus(ctx, orderID, model.Open)
 		require.NoError(t, err)
 		require.Len(t, eventDispatcher.events, 0)
 	})
@@ -141,23 +143,23 @@ type mockOrderRepository struct {
 	store map[uuid.UUID]*model.Order
 }
 
-func (m mockOrderRepository) NextID() (uuid.UUID, error) {
+func (m mockOrderRepository) NextID(_ context.Context) (uuid.UUID, error) {
 	return uuid.NewV7()
 }
 
-func (m mockOrderRepository) Store(order *model.Order) error {
+func (m mockOrderRepository) Store(_ context.Context, order *model.Order) error {
 	m.store[order.ID] = order
 	return nil
 }
 
-func (m mockOrderRepository) Find(id uuid.UUID) (*model.Order, error) {
+func (m mockOrderRepository) Find(_ context.Context, id uuid.UUID) (*model.Order, error) {
 	if order, ok := m.store[id]; ok && order.DeletedAt == nil {
 		return order, nil
 	}
 	return nil, model.ErrOrderNotFound
 }
 
-func (m mockOrderRepository) Delete(id uuid.UUID) error {
+func (m mockOrderRepository) Delete(_ context.Context, id uuid.UUID) error {
 	if order, ok := m.store[id]; ok && order.DeletedAt == nil {
 		order.DeletedAt = toPtr(time.Now())
 		return nil
@@ -171,7 +173,7 @@ type mockEventDispatcher struct {
 	events []service.Event
 }
 
-func (m *mockEventDispatcher) Dispatch(event service.Event) error {
+func (m *mockEventDispatcher) Dispatch(_ context.Context, event service.Event) error {
 	m.events = append(m.events, event)
 	return nil
 }
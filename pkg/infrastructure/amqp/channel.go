@@ -0,0 +1,82 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	libamqp "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/amqp"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var errChannelClosed = errors.New("amqp channel is closed")
+
+// reconnectingChannel holds channel of golib connection and reopens it when it is closed by broker,
+// closed connection is restored by golib connection which calls Connect of channel owner again
+type reconnectingChannel struct {
+	logger libamqp.Logger
+	// setup declares topology on opened channel, channel is closed when it fails
+	setup func(channel *amqp.Channel) error
+
+	mu      sync.RWMutex
+	channel *amqp.Channel
+}
+
+func (c *reconnectingChannel) open(conn *amqp.Connection) (err error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, channel.Close())
+		}
+	}()
+
+	err = c.setup(channel)
+	if err != nil {
+		return err
+	}
+
+	go c.processChannelErrors(conn, channel.NotifyClose(make(chan *amqp.Error, 1)))
+
+	c.mu.Lock()
+	c.channel = channel
+	c.mu.Unlock()
+	return nil
+}
+
+// current returns open channel or error if channel is not opened yet or closed
+func (c *reconnectingChannel) current() (*amqp.Channel, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.channel == nil || c.channel.IsClosed() {
+		return nil, errChannelClosed
+	}
+	return c.channel, nil
+}
+
+func (c *reconnectingChannel) processChannelErrors(conn *amqp.Connection, ch chan *amqp.Error) {
+	closeErr := <-ch
+	if closeErr == nil {
+		return
+	}
+
+	c.logger.Error(closeErr, "AMQP channel error, trying to reconnect")
+	for !conn.IsClosed() {
+		err := c.open(conn)
+		if err == nil {
+			c.logger.Info("AMQP channel restored")
+			return
+		}
+		c.logger.Error(err, "failed to reconnect to AMQP channel")
+		time.Sleep(time.Second)
+	}
+}
+
+// Ready returns error until channel is open, so it can be used as readiness check
+func (c *reconnectingChannel) Ready(context.Context) error {
+	_, err := c.current()
+	return err
+}
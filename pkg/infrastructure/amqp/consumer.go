@@ -0,0 +1,129 @@
+package amqp
+
+import (
+	"context"
+
+	libamqp "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/amqp"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler func(ctx context.Context, delivery Delivery) error
+
+// Consumer binds queue to exchange and passes deliveries to handler, delivery is acked when handler succeeds
+// and dropped otherwise. golib consumer is not used since it doesn't give headers and name of server named queue
+type Consumer struct {
+	*reconnectingChannel
+
+	exchange    libamqp.ExchangeConfig
+	queue       libamqp.QueueConfig
+	routingKeys []string
+	handler     Handler
+	logger      libamqp.Logger
+}
+
+func NewConsumer(
+	exchange libamqp.ExchangeConfig,
+	queue libamqp.QueueConfig,
+	routingKeys []string,
+	handler Handler,
+	logger libamqp.Logger,
+) *Consumer {
+	c := &Consumer{
+		exchange:    exchange,
+		queue:       queue,
+		routingKeys: routingKeys,
+		handler:     handler,
+		logger:      logger,
+	}
+	c.reconnectingChannel = &reconnectingChannel{logger: logger, setup: c.setup}
+	return c
+}
+
+func (c *Consumer) Connect(conn *amqp.Connection) error {
+	return c.open(conn)
+}
+
+func (c *Consumer) setup(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		c.exchange.Name,
+		c.exchange.Kind,
+		c.exchange.Durable,
+		c.exchange.AutoDelete,
+		c.exchange.Internal,
+		c.exchange.NoWait,
+		c.exchange.Args,
+	)
+	if err != nil {
+		return err
+	}
+	queue, err := channel.QueueDeclare(
+		c.queue.Name,
+		c.queue.Durable,
+		c.queue.AutoDelete,
+		c.queue.Exclusive,
+		c.queue.NoWait,
+		c.queue.Args,
+	)
+	if err != nil {
+		return err
+	}
+	for _, routingKey := range c.routingKeys {
+		err = channel.QueueBind(queue.Name, routingKey, c.exchange.Name, false, nil)
+		if err != nil {
+			return err
+		}
+	}
+	deliveries, err := channel.Consume(queue.Name, "", false, c.queue.Exclusive, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	go c.consume(deliveries)
+	return nil
+}
+
+func (c *Consumer) consume(deliveries <-chan amqp.Delivery) {
+	for delivery := range deliveries {
+		err := c.handle(delivery)
+		if err != nil {
+			c.logger.Error(err, "failed to handle AMQP delivery "+delivery.RoutingKey)
+			_ = delivery.Nack(false, false)
+			continue
+		}
+		_ = delivery.Ack(false)
+	}
+}
+
+// handle continues trace of publisher from delivery headers
+func (c *Consumer) handle(delivery amqp.Delivery) error {
+	ctx := context.Background()
+	if delivery.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headersCarrier(delivery.Headers))
+	}
+	ctx, span := tracer.Start(ctx, delivery.RoutingKey+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingRabbitmqDestinationRoutingKey(delivery.RoutingKey),
+		),
+	)
+	defer span.End()
+
+	err := c.handler(ctx, Delivery{
+		RoutingKey:  delivery.RoutingKey,
+		ContentType: delivery.ContentType,
+		Type:        delivery.Type,
+		Headers:     delivery.Headers,
+		Body:        delivery.Body,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	libamqp "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/amqp"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Delivery is golib delivery with headers, golib producer has no way to send them
+type Delivery struct {
+	RoutingKey  string
+	ContentType string
+	Type        string
+	Headers     amqp.Table
+	Body        []byte
+}
+
+// Producer publishes with confirmation to exchange like golib producer does.
+// It is registered in golib connection with AddChannel, so it is reconnected together with connection
+type Producer struct {
+	*reconnectingChannel
+
+	appID    string
+	exchange libamqp.ExchangeConfig
+}
+
+func NewProducer(appID string, exchange libamqp.ExchangeConfig, logger libamqp.Logger) *Producer {
+	p := &Producer{
+		appID:    appID,
+		exchange: exchange,
+	}
+	p.reconnectingChannel = &reconnectingChannel{logger: logger, setup: p.setup}
+	return p
+}
+
+func (p *Producer) Connect(conn *amqp.Connection) error {
+	return p.open(conn)
+}
+
+func (p *Producer) setup(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		p.exchange.Name,
+		p.exchange.Kind,
+		p.exchange.Durable,
+		p.exchange.AutoDelete,
+		p.exchange.Internal,
+		p.exchange.NoWait,
+		p.exchange.Args,
+	)
+	if err != nil {
+		return err
+	}
+	return channel.Confirm(false)
+}
+
+func (p *Producer) Publish(ctx context.Context, delivery Delivery) error {
+	channel, err := p.current()
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		p.exchange.Name,
+		delivery.RoutingKey,
+		true,
+		false,
+		amqp.Publishing{
+			Headers:      delivery.Headers,
+			ContentType:  delivery.ContentType,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+			Type:         delivery.Type,
+			AppId:        p.appID,
+			Body:         delivery.Body,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if confirmation == nil {
+		return nil
+	}
+	ok, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("failed to publish delivery")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const ProductUpdatedRoutingKey = "product.updated"
+
+// ProductUpdatedEvent is published by product service when product or its price is changed
+type ProductUpdatedEvent struct {
+	ProductID uuid.UUID `json:"product_id"`
+}
+
+// NewProductUpdatedHandler calls invalidate for every updated product
+func NewProductUpdatedHandler(invalidate func(productID uuid.UUID)) Handler {
+	return func(_ context.Context, delivery Delivery) error {
+		var event ProductUpdatedEvent
+		err := json.Unmarshal(delivery.Body, &event)
+		if err != nil {
+			return err
+		}
+		invalidate(event.ProductID)
+		return nil
+	}
+}
@@ -4,15 +4,21 @@ import (
 	"context"
 	"encoding/json"
 
-	libamqp "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/amqp"
 	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("order/pkg/infrastructure/amqp")
+
 type EventPublisher struct {
-	producer libamqp.Producer
+	producer *Producer
 }
 
-func NewEventPublisher(producer libamqp.Producer) *EventPublisher {
+func NewEventPublisher(producer *Producer) *EventPublisher {
 	return &EventPublisher{producer: producer}
 }
 
@@ -28,10 +34,53 @@ func (p *EventPublisher) PublishOrderCreated(ctx context.Context, event OrderCre
 		return err
 	}
 
-	return p.producer.Publish(ctx, libamqp.Delivery{
+	return p.publish(ctx, Delivery{
 		RoutingKey:  "order.created",
 		ContentType: "application/json",
 		Type:        "order.created",
 		Body:        body,
 	})
 }
+
+// publish sends delivery with trace context in headers, so consumers continue trace of publisher
+func (p *EventPublisher) publish(ctx context.Context, delivery Delivery) error {
+	ctx, span := tracer.Start(ctx, delivery.RoutingKey+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingRabbitmqDestinationRoutingKey(delivery.RoutingKey),
+		),
+	)
+	defer span.End()
+
+	delivery.Headers = amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headersCarrier(delivery.Headers))
+
+	err := p.producer.Publish(ctx, delivery)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// headersCarrier adapts AMQP headers to OTel propagator
+type headersCarrier amqp.Table
+
+func (c headersCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c headersCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+func (c headersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+	"order/pkg/infrastructure/auth"
+)
+
+func TestVerifier(t *testing.T) {
+	key := newKey(t, "key-1")
+	otherKey := newKey(t, "key-2")
+	verifier := newVerifier(t, auth.Config{Issuer: "auth", Audience: "order"}, key)
+	userID := uuid.New()
+
+	t.Run("Valid token gives identity", func(t *testing.T) {
+		token := sign(t, key, claims(userID.String()))
+
+		identity, err := verifier.Verify(token)
+		require.NoError(t, err)
+		require.Equal(t, userID, identity.UserID)
+		require.True(t, identity.HasRole("customer"))
+	})
+
+	t.Run("Invalid tokens are rejected as unauthenticated", func(t *testing.T) {
+		expired := claims(userID.String())
+		expired["exp"] = time.Now().Add(-time.Hour).Unix()
+		wrongAudience := claims(userID.String())
+		wrongAudience["aud"] = "payment"
+		withoutExpiry := claims(userID.String())
+		delete(withoutExpiry, "exp")
+
+		for name, token := range map[string]string{
+			"malformed":          "not a token",
+			"unknown key":        sign(t, otherKey, claims(userID.String())),
+			"expired":            sign(t, key, expired),
+			"wrong audience":     sign(t, key, wrongAudience),
+			"without expiry":     sign(t, key, withoutExpiry),
+			"subject not a user": sign(t, key, claims("service-account")),
+			"other algorithm":    signWithAlgorithm(t, key, jose.PS256, claims(userID.String())),
+		} {
+			_, err := verifier.Verify(token)
+			require.ErrorIs(t, err, service.ErrUnauthenticated, name)
+		}
+	})
+
+	t.Run("Symmetric key without alg allows only HMAC algorithms", func(t *testing.T) {
+		secret := jose.JSONWebKey{Key: []byte("0123456789abcdef0123456789abcdef"), KeyID: "secret"}
+		jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{secret}})
+		require.NoError(t, err)
+		hmacVerifier, err := auth.NewVerifier(auth.Config{JWKS: string(jwks)})
+		require.NoError(t, err)
+
+		_, err = hmacVerifier.Verify(signWithAlgorithm(t, secret, jose.HS256, claims(userID.String())))
+		require.NoError(t, err)
+		_, err = hmacVerifier.Verify(sign(t, key, claims(userID.String())))
+		require.ErrorIs(t, err, service.ErrUnauthenticated)
+	})
+
+	t.Run("Key set without keys is rejected", func(t *testing.T) {
+		_, err := auth.NewVerifier(auth.Config{JWKS: `{"keys":[]}`})
+		require.Error(t, err)
+	})
+}
+
+func newKey(t *testing.T, keyID string) jose.JSONWebKey {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return jose.JSONWebKey{Key: privateKey, KeyID: keyID, Algorithm: string(jose.RS256), Use: "sig"}
+}
+
+func newVerifier(t *testing.T, config auth.Config, key jose.JSONWebKey) *auth.Verifier {
+	t.Helper()
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{key.Public()}})
+	require.NoError(t, err)
+	config.JWKS = string(jwks)
+	verifier, err := auth.NewVerifier(config)
+	require.NoError(t, err)
+	return verifier
+}
+
+func claims(subject string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   subject,
+		"iss":   "auth",
+		"aud":   "order",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"customer"},
+	}
+}
+
+func sign(t *testing.T, key jose.JSONWebKey, claims map[string]interface{}) string {
+	t.Helper()
+	return signWithAlgorithm(t, key, jose.RS256, claims)
+}
+
+func signWithAlgorithm(t *testing.T, key jose.JSONWebKey, algorithm jose.SignatureAlgorithm, claims map[string]interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: algorithm, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
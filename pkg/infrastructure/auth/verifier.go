@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"order/pkg/application/service"
+)
+
+type Config struct {
+	// JWKSFile is path to JSON Web Key Set, JWKS is used if it is empty
+	JWKSFile string
+	// JWKS is JSON Web Key Set passed inline
+	JWKS string
+	// Issuer and Audience are checked only if not empty
+	Issuer   string
+	Audience string
+	// Leeway is allowed clock skew for exp, nbf and iat claims
+	Leeway time.Duration
+}
+
+type claims struct {
+	jwt.Claims
+	Roles []string `json:"roles,omitempty"`
+}
+
+type Verifier struct {
+	keys jose.JSONWebKeySet
+	// algorithms are allowed by any of keys, token algorithm is checked against its key after key lookup
+	algorithms []jose.SignatureAlgorithm
+	issuer     string
+	audience   string
+	leeway     time.Duration
+}
+
+func NewVerifier(config Config) (*Verifier, error) {
+	data := []byte(config.JWKS)
+	if config.JWKSFile != "" {
+		var err error
+		data, err = os.ReadFile(config.JWKSFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, errors.New("JWKS file or JWKS must be set when authentication is enabled")
+	}
+
+	var keys jose.JSONWebKeySet
+	err := json.Unmarshal(data, &keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWKS")
+	}
+	if len(keys.Keys) == 0 {
+		return nil, errors.New("JWKS has no keys")
+	}
+
+	var algorithms []jose.SignatureAlgorithm
+	for _, key := range keys.Keys {
+		keyAlgorithms := allowedAlgorithms(key)
+		if len(keyAlgorithms) == 0 {
+			return nil, errors.Errorf("JWKS key %q has unsupported algorithm or key type", key.KeyID)
+		}
+		for _, algorithm := range keyAlgorithms {
+			if !slices.Contains(algorithms, algorithm) {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+	}
+
+	return &Verifier{
+		keys:       keys,
+		algorithms: algorithms,
+		issuer:     config.Issuer,
+		audience:   config.Audience,
+		leeway:     config.Leeway,
+	}, nil
+}
+
+// Verify checks token signature and claims, subject of token must be user ID
+func (v *Verifier) Verify(token string) (service.Identity, error) {
+	parsed, err := jwt.ParseSigned(token, v.algorithms)
+	if err != nil {
+		return service.Identity{}, errors.Wrap(service.ErrUnauthenticated, "malformed token")
+	}
+
+	key, err := v.key(parsed.Headers)
+	if err != nil {
+		return service.Identity{}, err
+	}
+
+	var c claims
+	err = parsed.Claims(key.Key, &c)
+	if err != nil {
+		return service.Identity{}, errors.Wrap(service.ErrUnauthenticated, "invalid token signature")
+	}
+
+	expected := jwt.Expected{
+		Issuer: v.issuer,
+		Time:   time.Now(),
+	}
+	if v.audience != "" {
+		expected.AnyAudience = jwt.Audience{v.audience}
+	}
+	err = c.ValidateWithLeeway(expected, v.leeway)
+	if err != nil {
+		return service.Identity{}, errors.Wrap(service.ErrUnauthenticated, err.Error())
+	}
+	if c.Expiry == nil {
+		return service.Identity{}, errors.Wrap(service.ErrUnauthenticated, "token without expiration time")
+	}
+
+	userID, err := uuid.Parse(c.Subject)
+	if err != nil {
+		return service.Identity{}, errors.Wrap(service.ErrUnauthenticated, "token subject is not user ID")
+	}
+	return service.Identity{
+		UserID:  userID,
+		Subject: c.Subject,
+		Roles:   c.Roles,
+	}, nil
+}
+
+// key finds key by kid, token without kid is accepted only if set has single key.
+// Token algorithm must be allowed for found key, so token can't be signed with algorithm of other key
+func (v *Verifier) key(headers []jose.Header) (jose.JSONWebKey, error) {
+	if len(headers) != 1 {
+		return jose.JSONWebKey{}, errors.Wrap(service.ErrUnauthenticated, "token must have single signature")
+	}
+	header := headers[0]
+
+	key, err := v.findKey(header)
+	if err != nil {
+		return jose.JSONWebKey{}, err
+	}
+	if !slices.Contains(allowedAlgorithms(key), jose.SignatureAlgorithm(header.Algorithm)) {
+		return jose.JSONWebKey{}, errors.Wrapf(service.ErrUnauthenticated, "algorithm %q is not allowed for key", header.Algorithm)
+	}
+	return key, nil
+}
+
+func (v *Verifier) findKey(header jose.Header) (jose.JSONWebKey, error) {
+	if header.KeyID == "" {
+		if len(v.keys.Keys) == 1 {
+			return v.keys.Keys[0], nil
+		}
+		return jose.JSONWebKey{}, errors.Wrap(service.ErrUnauthenticated, "token without key ID")
+	}
+	keys := v.keys.Key(header.KeyID)
+	if len(keys) == 0 {
+		return jose.JSONWebKey{}, errors.Wrapf(service.ErrUnauthenticated, "unknown key ID %q", header.KeyID)
+	}
+	return keys[0], nil
+}
+
+// allowedAlgorithms gives algorithm from alg of key or algorithms matching key type if alg is not set
+func allowedAlgorithms(key jose.JSONWebKey) []jose.SignatureAlgorithm {
+	if key.Algorithm != "" {
+		return []jose.SignatureAlgorithm{jose.SignatureAlgorithm(key.Algorithm)}
+	}
+	if !key.IsPublic() {
+		if _, ok := key.Key.([]byte); ok {
+			return []jose.SignatureAlgorithm{jose.HS256, jose.HS384, jose.HS512}
+		}
+		key = key.Public()
+	}
+	switch publicKey := key.Key.(type) {
+	case *rsa.PublicKey:
+		return []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512}
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256():
+			return []jose.SignatureAlgorithm{jose.ES256}
+		case elliptic.P384():
+			return []jose.SignatureAlgorithm{jose.ES384}
+		case elliptic.P521():
+			return []jose.SignatureAlgorithm{jose.ES512}
+		}
+	case ed25519.PublicKey:
+		return []jose.SignatureAlgorithm{jose.EdDSA}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+
+	"order/pkg/application/service"
+)
+
+type Config struct {
+	TTL time.Duration
+	// MaxSize bounds count of cached products, least recently used are evicted first
+	MaxSize int
+}
+
+// ProductLister returns prices of all products to warm cache up
+type ProductLister interface {
+	ListPrices(ctx context.Context) (map[uuid.UUID]float64, error)
+}
+
+// ProductPriceCache is ProductService decorator keeping prices in memory,
+// concurrent lookups of the same product share single call to product service
+type ProductPriceCache struct {
+	next   service.ProductService
+	config Config
+
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]*list.Element
+	lru     *list.List
+	// version is incremented on invalidation, so lookups started before it don't store stale prices
+	version uint64
+}
+
+type entry struct {
+	productID uuid.UUID
+	price     float64
+	expiresAt time.Time
+}
+
+func NewProductPriceCache(next service.ProductService, config Config) *ProductPriceCache {
+	return &ProductPriceCache{
+		next:    next,
+		config:  config,
+		entries: make(map[uuid.UUID]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *ProductPriceCache) GetPrice(ctx context.Context, productID uuid.UUID) (float64, error) {
+	if price, ok := c.get(productID); ok {
+		return price, nil
+	}
+
+	price, err, _ := c.group.Do(productID.String(), func() (interface{}, error) {
+		version := c.currentVersion()
+		// lookup is not cancelled by caller which started it, other callers may wait for it
+		price, err := c.next.GetPrice(context.WithoutCancel(ctx), productID)
+		if err != nil {
+			return 0.0, err
+		}
+		c.set(productID, price, version)
+		return price, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return price.(float64), nil
+}
+
+// Warmup fills cache with prices of all products
+func (c *ProductPriceCache) Warmup(ctx context.Context, lister ProductLister) (int, error) {
+	version := c.currentVersion()
+	prices, err := lister.ListPrices(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for productID, price := range prices {
+		c.set(productID, price, version)
+	}
+	return len(prices), nil
+}
+
+// Invalidate removes product price, so next lookup goes to product service
+func (c *ProductPriceCache) Invalidate(productID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	if element, ok := c.entries[productID]; ok {
+		c.remove(element)
+	}
+	c.group.Forget(productID.String())
+}
+
+func (c *ProductPriceCache) get(productID uuid.UUID) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[productID]
+	if !ok {
+		return 0, false
+	}
+	e := element.Value.(*entry)
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(element)
+		return 0, false
+	}
+	c.lru.MoveToFront(element)
+	return e.price, true
+}
+
+func (c *ProductPriceCache) set(productID uuid.UUID, price float64, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version || c.config.MaxSize <= 0 {
+		return
+	}
+	expiresAt := time.Now().Add(c.config.TTL)
+	if element, ok := c.entries[productID]; ok {
+		e := element.Value.(*entry)
+		e.price = price
+		e.expiresAt = expiresAt
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[productID] = c.lru.PushFront(&entry{
+		productID: productID,
+		price:     price,
+		expiresAt: expiresAt,
+	})
+	for c.lru.Len() > c.config.MaxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *ProductPriceCache) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*entry).productID)
+}
+
+func (c *ProductPriceCache) currentVersion() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Len returns count of cached products including expired ones not evicted yet
+func (c *ProductPriceCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
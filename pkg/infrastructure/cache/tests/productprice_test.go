@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	infraamqp "order/pkg/infrastructure/amqp"
+	"order/pkg/infrastructure/cache"
+)
+
+func TestProductPriceCache(t *testing.T) {
+	ctx := context.Background()
+	productID := uuid.New()
+
+	t.Run("Price is requested once until expired", func(t *testing.T) {
+		products := newMockProductService(10)
+		priceCache := cache.NewProductPriceCache(products, cache.Config{TTL: 50 * time.Millisecond, MaxSize: 10})
+
+		for i := 0; i < 3; i++ {
+			price, err := priceCache.GetPrice(ctx, productID)
+			require.NoError(t, err)
+			require.Equal(t, 10.0, price)
+		}
+		require.EqualValues(t, 1, products.calls.Load())
+
+		time.Sleep(60 * time.Millisecond)
+		_, err := priceCache.GetPrice(ctx, productID)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, products.calls.Load())
+	})
+
+	t.Run("Least recently used product is evicted", func(t *testing.T) {
+		products := newMockProductService(10)
+		priceCache := cache.NewProductPriceCache(products, cache.Config{TTL: time.Minute, MaxSize: 2})
+		first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+		for _, id := range []uuid.UUID{first, second, first, third} {
+			_, err := priceCache.GetPrice(ctx, id)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 2, priceCache.Len())
+		require.EqualValues(t, 3, products.calls.Load())
+
+		_, err := priceCache.GetPrice(ctx, first)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, products.calls.Load())
+		_, err = priceCache.GetPrice(ctx, second)
+		require.NoError(t, err)
+		require.EqualValues(t, 4, products.calls.Load())
+	})
+
+	t.Run("Concurrent lookups share single call", func(t *testing.T) {
+		products := newMockProductService(10)
+		products.release = make(chan struct{})
+		priceCache := cache.NewProductPriceCache(products, cache.Config{TTL: time.Minute, MaxSize: 10})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				price, err := priceCache.GetPrice(ctx, productID)
+				require.NoError(t, err)
+				require.Equal(t, 10.0, price)
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(products.release)
+		wg.Wait()
+
+		require.EqualValues(t, 1, products.calls.Load())
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		products := newMockProductService(10)
+		products.err = errors.New("product service is down")
+		priceCache := cache.NewProductPriceCache(products, cache.Config{TTL: time.Minute, MaxSize: 10})
+
+		_, err := priceCache.GetPrice(ctx, productID)
+		require.ErrorIs(t, err, products.err)
+
+		products.err = nil
+		price, err := priceCache.GetPrice(ctx, productID)
+		require.NoError(t, err)
+		require.Equal(t, 10.0, price)
+	})
+
+	t.Run("Product updated event invalidates price", func(t *testing.T) {
+		products := newMockProductService(10)
+		priceCache := cache.NewProductPriceCache(products, cache.Config{TTL: time.Minute, MaxSize: 10})
+		handler := infraamqp.NewProductUpdatedHandler(priceCache.Invalidate)
+
+		_, err := priceCache.GetPrice(ctx, productID)
+		require.NoError(t, err)
+
+		products.setPrice(20)
+		body, err := json.Marshal(infraamqp.ProductUpdatedEvent{ProductID: productID})
+		require.NoError(t, err)
+		require.NoError(t, handler(ctx, infraamqp.Delivery{RoutingKey: infraamqp.ProductUpdatedRoutingKey, Body: body}))
+
+		price, err := priceCache.GetPrice(ctx, productID)
+		require.NoError(t, err)
+		require.Equal(t, 20.0, price)
+	})
+
+	t.Run("Price loaded before invalidation is not cached", func(t *testing.T) {
+		products := newMockProductService(10)
+		products.release = make(chan struct{})
+		priceCache := cache.NewProductPriceCache(products, cache.Config{TTL: time.Minute, MaxSize: 10})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = priceCache.GetPrice(ctx, productID)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		priceCache.Invalidate(productID)
+		close(products.release)
+		<-done
+
+		require.Equal(t, 0, priceCache.Len())
+	})
+
+	t.Run("Warmup loads all prices", func(t *testing.T) {
+		products := newMockProductService(10)
+		priceCache := cache.NewProductPriceCache(products, cache.Config{TTL: time.Minute, MaxSize: 10})
+
+		count, err := priceCache.Warmup(ctx, products)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		price, err := priceCache.GetPrice(ctx, products.listedProductID)
+		require.NoError(t, err)
+		require.Equal(t, 15.0, price)
+		require.EqualValues(t, 0, products.calls.Load())
+	})
+}
+
+type mockProductService struct {
+	mu              sync.Mutex
+	price           float64
+	err             error
+	release         chan struct{}
+	calls           atomic.Int32
+	listedProductID uuid.UUID
+}
+
+func newMockProductService(price float64) *mockProductService {
+	return &mockProductService{price: price, listedProductID: uuid.New()}
+}
+
+func (m *mockProductService) GetPrice(_ context.Context, _ uuid.UUID) (float64, error) {
+	m.calls.Add(1)
+	if m.release != nil {
+		<-m.release
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.price, m.err
+}
+
+func (m *mockProductService) ListPrices(_ context.Context) (map[uuid.UUID]float64, error) {
+	return map[uuid.UUID]float64{m.listedProductID: 15}, nil
+}
+
+func (m *mockProductService) setPrice(price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.price = price
+}
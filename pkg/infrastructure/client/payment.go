@@ -6,8 +6,11 @@ import (
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	paymentapi "order/api/client/paymentserviceinternal"
+	"order/pkg/application/service"
 )
 
 type PaymentClient struct {
@@ -26,6 +29,13 @@ func (c *PaymentClient) ProcessPayment(ctx context.Context, userID, orderID uuid
 		OrderID: orderID.String(),
 		Amount:  amount,
 	})
+	// payment service rejects payment it declines as request which can't be fulfilled in current state
+	switch status.Code(err) {
+	case codes.FailedPrecondition:
+		return fmt.Errorf("%w: %s", service.ErrPaymentDeclined, status.Convert(err).Message())
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", service.ErrInvalidPaymentRequest, status.Convert(err).Message())
+	}
 	if err != nil {
 		return fmt.Errorf("failed to process payment: %w", err)
 	}
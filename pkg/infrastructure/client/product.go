@@ -6,8 +6,11 @@ import (
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	productapi "order/api/client/productinternal"
+	"order/pkg/application/service"
 )
 
 type ProductClient struct {
@@ -24,11 +27,30 @@ func (c *ProductClient) GetPrice(ctx context.Context, productID uuid.UUID) (floa
 	resp, err := c.client.FindProduct(ctx, &productapi.FindProductRequest{
 		ProductID: productID.String(),
 	})
+	if status.Code(err) == codes.NotFound {
+		return 0, fmt.Errorf("%w: %s", service.ErrProductNotFound, productID)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to find product: %w", err)
 	}
 	if resp.Product == nil {
-		return 0, fmt.Errorf("product not found")
+		return 0, fmt.Errorf("%w: %s", service.ErrProductNotFound, productID)
 	}
 	return resp.Product.Price, nil
 }
+
+func (c *ProductClient) ListPrices(ctx context.Context) (map[uuid.UUID]float64, error) {
+	resp, err := c.client.ListProducts(ctx, &productapi.ListProductsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	prices := make(map[uuid.UUID]float64, len(resp.Products))
+	for _, product := range resp.Products {
+		productID, err := uuid.Parse(product.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID %q: %w", product.ProductID, err)
+		}
+		prices[productID] = product.Price
+	}
+	return prices, nil
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sony/gobreaker/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Config struct {
+	// Timeout is deadline of whole call including retry attempts and backoff, zero means no timeout
+	Timeout time.Duration
+	// MaxAttempts includes first call, retries are disabled with value less than 2
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// retryableCodes are returned when downstream service didn't process request, so retry is safe for idempotent calls
+var retryableCodes = []string{
+	"UNAVAILABLE",
+	"RESOURCE_EXHAUSTED",
+}
+
+// ServiceConfig builds gRPC service config applying timeout and retry policy to all methods,
+// use grpc.WithDefaultServiceConfig to apply it to connection
+func ServiceConfig(config Config) (string, error) {
+	type retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	type methodConfig struct {
+		Name        []struct{}   `json:"name"`
+		Timeout     string       `json:"timeout,omitempty"`
+		RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+	}
+
+	method := methodConfig{
+		// empty name matches all methods of all services
+		Name: []struct{}{{}},
+	}
+	if config.Timeout > 0 {
+		method.Timeout = durationString(config.Timeout)
+	}
+	if config.MaxAttempts > 1 {
+		if config.InitialBackoff <= 0 || config.MaxBackoff <= 0 {
+			return "", errors.New("retry backoff must be positive")
+		}
+		method.RetryPolicy = &retryPolicy{
+			MaxAttempts:          config.MaxAttempts,
+			InitialBackoff:       durationString(config.InitialBackoff),
+			MaxBackoff:           durationString(config.MaxBackoff),
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: retryableCodes,
+		}
+	}
+
+	serviceConfig, err := json.Marshal(struct {
+		MethodConfig []methodConfig `json:"methodConfig"`
+	}{
+		MethodConfig: []methodConfig{method},
+	})
+	return string(serviceConfig), err
+}
+
+// durationString formats duration as protobuf JSON duration expected by service config
+func durationString(d time.Duration) string {
+	return fmt.Sprintf("%.9fs", d.Seconds())
+}
+
+type CircuitBreakerConfig struct {
+	// MaxFailures is count of consecutive failures opening breaker
+	MaxFailures uint32
+	// OpenTimeout is how long breaker stays open before probe requests are allowed
+	OpenTimeout time.Duration
+}
+
+// CircuitBreaker fails calls fast while downstream service is unhealthy
+type CircuitBreaker struct {
+	name    string
+	breaker *gobreaker.CircuitBreaker[struct{}]
+}
+
+// NewCircuitBreaker calls onStateChange with gobreaker states: 0 closed, 1 half-open, 2 open
+func NewCircuitBreaker(name string, config CircuitBreakerConfig, onStateChange func(name string, state int)) *CircuitBreaker {
+	return &CircuitBreaker{
+		name: name,
+		breaker: gobreaker.NewCircuitBreaker[struct{}](gobreaker.Settings{
+			Name:    name,
+			Timeout: config.OpenTimeout,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.ConsecutiveFailures >= config.MaxFailures
+			},
+			OnStateChange: func(name string, _ gobreaker.State, to gobreaker.State) {
+				if onStateChange != nil {
+					onStateChange(name, int(to))
+				}
+			},
+			IsSuccessful: isDownstreamHealthy,
+		}),
+	}
+}
+
+// Interceptor must be chained on connection, calls are counted after all retries are done
+func (b *CircuitBreaker) Interceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var callErr error
+		_, err := b.breaker.Execute(func() (struct{}, error) {
+			callErr = invoker(ctx, method, req, reply, cc, opts...)
+			if ctx.Err() != nil {
+				// call is cancelled or timed out by caller, downstream service is not to blame
+				return struct{}{}, nil
+			}
+			return struct{}{}, callErr
+		})
+		if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+			return status.Errorf(codes.Unavailable, "%s: %v", b.name, err)
+		}
+		return callErr
+	}
+}
+
+// Ready fails while breaker is open, half-open breaker is considered ready to let probe requests through
+func (b *CircuitBreaker) Ready(_ context.Context) error {
+	if b.breaker.State() == gobreaker.StateOpen {
+		return fmt.Errorf("%s: %w", b.name, gobreaker.ErrOpenState)
+	}
+	return nil
+}
+
+// isDownstreamHealthy doesn't count business errors and cancellation by caller as failures of downstream service
+func isDownstreamHealthy(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable,
+		codes.DeadlineExceeded,
+		codes.ResourceExhausted,
+		codes.Internal,
+		codes.Unknown:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	paymentapi "order/api/client/paymentserviceinternal"
+	"order/pkg/application/service"
+	"order/pkg/infrastructure/client"
+)
+
+func TestPaymentClient(t *testing.T) {
+	processPayment := func(t *testing.T, code codes.Code) error {
+		t.Helper()
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		server := grpc.NewServer()
+		paymentapi.RegisterPaymentServiceInternalServer(server, &paymentServer{code: code})
+		go func() {
+			_ = server.Serve(listener)
+		}()
+		t.Cleanup(server.Stop)
+
+		conn := dial(t, listener.Addr().String(), client.Config{MaxAttempts: 1})
+		return client.NewPaymentClient(conn).ProcessPayment(context.Background(), uuid.New(), uuid.New(), 10)
+	}
+
+	t.Run("Failed precondition is declined payment", func(t *testing.T) {
+		err := processPayment(t, codes.FailedPrecondition)
+		require.ErrorIs(t, err, service.ErrPaymentDeclined)
+	})
+
+	t.Run("Invalid argument is invalid request and not declined payment", func(t *testing.T) {
+		err := processPayment(t, codes.InvalidArgument)
+		require.ErrorIs(t, err, service.ErrInvalidPaymentRequest)
+		require.NotErrorIs(t, err, service.ErrPaymentDeclined)
+	})
+
+	t.Run("Other errors are returned as is", func(t *testing.T) {
+		err := processPayment(t, codes.Unavailable)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+		require.NotErrorIs(t, err, service.ErrPaymentDeclined)
+	})
+}
+
+type paymentServer struct {
+	paymentapi.UnimplementedPaymentServiceInternalServer
+	code codes.Code
+}
+
+func (s *paymentServer) ProcessPayment(context.Context, *paymentapi.ProcessPaymentRequest) (*paymentapi.ProcessPaymentResponse, error) {
+	return nil, status.Error(s.code, "payment rejected")
+}
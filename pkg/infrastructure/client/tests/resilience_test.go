@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"order/pkg/infrastructure/client"
+)
+
+func TestServiceConfig(t *testing.T) {
+	t.Run("Retries only retryable codes", func(t *testing.T) {
+		server := serve(t, codes.Unavailable, codes.Unavailable)
+		conn := dial(t, server.address, client.Config{
+			Timeout:        time.Second,
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		})
+
+		require.NoError(t, check(conn))
+		require.EqualValues(t, 3, server.calls.Load())
+
+		server = serve(t, codes.InvalidArgument)
+		conn = dial(t, server.address, client.Config{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		})
+
+		require.Equal(t, codes.InvalidArgument, status.Code(check(conn)))
+		require.EqualValues(t, 1, server.calls.Load())
+	})
+
+	t.Run("Single attempt is not retried", func(t *testing.T) {
+		server := serve(t, codes.Unavailable)
+		conn := dial(t, server.address, client.Config{MaxAttempts: 1})
+
+		require.Equal(t, codes.Unavailable, status.Code(check(conn)))
+		require.EqualValues(t, 1, server.calls.Load())
+	})
+
+	t.Run("Timeout limits call", func(t *testing.T) {
+		server := serve(t)
+		server.delay = time.Second
+		conn := dial(t, server.address, client.Config{Timeout: 10 * time.Millisecond})
+
+		require.Equal(t, codes.DeadlineExceeded, status.Code(check(conn)))
+	})
+
+	t.Run("Retries require backoff", func(t *testing.T) {
+		_, err := client.ServiceConfig(client.Config{MaxAttempts: 3})
+		require.Error(t, err)
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("Opens after consecutive failures and fails fast", func(t *testing.T) {
+		var states []int
+		breaker := client.NewCircuitBreaker("product", client.CircuitBreakerConfig{
+			MaxFailures: 2,
+			OpenTimeout: time.Minute,
+		}, func(_ string, state int) {
+			states = append(states, state)
+		})
+		server := serve(t, codes.Unavailable, codes.Unavailable, codes.Unavailable)
+		conn := dial(t, server.address, client.Config{}, grpc.WithUnaryInterceptor(breaker.Interceptor()))
+
+		require.NoError(t, breaker.Ready(context.Background()))
+		require.Error(t, check(conn))
+		require.Error(t, check(conn))
+		require.Equal(t, []int{2}, states)
+		require.Error(t, breaker.Ready(context.Background()))
+
+		require.Equal(t, codes.Unavailable, status.Code(check(conn)))
+		require.EqualValues(t, 2, server.calls.Load())
+	})
+
+	t.Run("Business errors don't open breaker", func(t *testing.T) {
+		breaker := client.NewCircuitBreaker("product", client.CircuitBreakerConfig{
+			MaxFailures: 1,
+			OpenTimeout: time.Minute,
+		}, nil)
+		server := serve(t, codes.NotFound, codes.InvalidArgument)
+		conn := dial(t, server.address, client.Config{}, grpc.WithUnaryInterceptor(breaker.Interceptor()))
+
+		require.Equal(t, codes.NotFound, status.Code(check(conn)))
+		require.Equal(t, codes.InvalidArgument, status.Code(check(conn)))
+		require.NoError(t, check(conn))
+		require.NoError(t, breaker.Ready(context.Background()))
+	})
+
+	t.Run("Half-open breaker closes after successful probe", func(t *testing.T) {
+		breaker := client.NewCircuitBreaker("product", client.CircuitBreakerConfig{
+			MaxFailures: 1,
+			OpenTimeout: 10 * time.Millisecond,
+		}, nil)
+		server := serve(t, codes.Unavailable)
+		conn := dial(t, server.address, client.Config{}, grpc.WithUnaryInterceptor(breaker.Interceptor()))
+
+		require.Error(t, check(conn))
+		require.Error(t, breaker.Ready(context.Background()))
+
+		time.Sleep(20 * time.Millisecond)
+		require.NoError(t, check(conn))
+		require.NoError(t, breaker.Ready(context.Background()))
+	})
+}
+
+// flakyHealthServer fails calls with given codes in order and then succeeds
+type flakyHealthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	address string
+	codes   []codes.Code
+	delay   time.Duration
+	calls   atomic.Int32
+}
+
+func (s *flakyHealthServer) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	call := int(s.calls.Add(1))
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if call <= len(s.codes) {
+		return nil, status.Error(s.codes[call-1], "flaky")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func serve(t *testing.T, failures ...codes.Code) *flakyHealthServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	flaky := &flakyHealthServer{address: listener.Addr().String(), codes: failures}
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, flaky)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return flaky
+}
+
+func dial(t *testing.T, address string, config client.Config, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	serviceConfig, err := client.ServiceConfig(config)
+	require.NoError(t, err)
+	conn, err := grpc.NewClient(address, append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func check(conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
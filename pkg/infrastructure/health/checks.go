@@ -0,0 +1,41 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	temporalclient "go.temporal.io/sdk/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+func DatabaseCheck(client mysql.ClientContext) Check {
+	return func(ctx context.Context) error {
+		var result int
+		return client.GetContext(ctx, &result, `SELECT 1`)
+	}
+}
+
+func TemporalCheck(client temporalclient.Client) Check {
+	return func(ctx context.Context) error {
+		_, err := client.CheckHealth(ctx, &temporalclient.CheckHealthRequest{})
+		return err
+	}
+}
+
+// GRPCConnectionCheck fails only for broken connection, idle connection is asked to connect
+// since grpc connects lazily and doesn't reconnect idle connection until first call
+func GRPCConnectionCheck(conn *grpc.ClientConn) Check {
+	return func(context.Context) error {
+		switch state := conn.GetState(); state {
+		case connectivity.Idle:
+			conn.Connect()
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("connection to %s is in %s state", conn.Target(), state)
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// UpdateGRPCServingStatus sets status of whole server and given services from checker report every interval
+// until ctx is done. Server shutdown sets NOT_SERVING which is not overwritten by later updates
+func UpdateGRPCServingStatus(
+	ctx context.Context,
+	checker *Checker,
+	server *grpchealth.Server,
+	interval time.Duration,
+	services ...string,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if checker.Check(ctx).Status != StatusUp {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		server.SetServingStatus("", status)
+		for _, service := range services {
+			server.SetServingStatus(service, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/infrastructure/health"
+)
+
+func TestChecker(t *testing.T) {
+	t.Run("Failed dependency is reported with 503", func(t *testing.T) {
+		checker := health.NewChecker(health.Config{})
+		checker.Register("database", func(context.Context) error { return nil })
+		checker.Register("amqp", func(context.Context) error { return errors.New("amqp channel is closed") })
+
+		recorder := httptest.NewRecorder()
+		checker.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+		var report health.Report
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+		require.Equal(t, health.StatusDown, report.Status)
+		require.Equal(t, health.StatusUp, report.Checks["database"].Status)
+		require.Equal(t, health.StatusDown, report.Checks["amqp"].Status)
+		require.Equal(t, "amqp channel is closed", report.Checks["amqp"].Error)
+	})
+
+	t.Run("Hanging check fails by timeout", func(t *testing.T) {
+		checker := health.NewChecker(health.Config{Timeout: 10 * time.Millisecond})
+		checker.Register("temporal", func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		start := time.Now()
+		report := checker.Check(context.Background())
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+		require.Equal(t, health.StatusDown, report.Checks["temporal"].Status)
+		require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["temporal"].Error)
+	})
+
+	t.Run("Report is cached", func(t *testing.T) {
+		checker := health.NewChecker(health.Config{CacheTTL: time.Hour})
+		calls := 0
+		checker.Register("database", func(context.Context) error {
+			calls++
+			return nil
+		})
+
+		checker.Check(context.Background())
+		report := checker.Check(context.Background())
+		require.Equal(t, 1, calls)
+		require.Equal(t, health.StatusUp, report.Status)
+	})
+}
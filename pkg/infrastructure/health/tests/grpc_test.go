@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"order/pkg/infrastructure/health"
+)
+
+func TestUpdateGRPCServingStatus(t *testing.T) {
+	const service = "orderinternal.OrderInternalService"
+
+	var failing atomic.Bool
+	checker := health.NewChecker(health.Config{CacheTTL: time.Nanosecond})
+	checker.Register("database", func(context.Context) error {
+		if failing.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	server := grpchealth.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		health.UpdateGRPCServingStatus(ctx, checker, server, time.Millisecond, service)
+		close(done)
+	}()
+
+	requireStatus := func(expected healthpb.HealthCheckResponse_ServingStatus) {
+		require.Eventually(t, func() bool {
+			resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			return err == nil && resp.Status == expected
+		}, time.Second, time.Millisecond)
+	}
+
+	requireStatus(healthpb.HealthCheckResponse_SERVING)
+	failing.Store(true)
+	requireStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	failing.Store(false)
+	requireStatus(healthpb.HealthCheckResponse_SERVING)
+
+	server.Shutdown()
+	requireStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	cancel()
+	<-done
+}
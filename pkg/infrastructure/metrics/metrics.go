@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "order"
+
+// Metrics holds all service collectors in own registry, so tests can create independent instances
+type Metrics struct {
+	registry *prometheus.Registry
+
+	GRPCRequests        *prometheus.CounterVec
+	GRPCRequestDuration *prometheus.HistogramVec
+	OutboundDuration    *prometheus.HistogramVec
+	CircuitBreakerState *prometheus.GaugeVec
+
+	UnitOfWorkDuration prometheus.Histogram
+	UnitOfWorkFailures prometheus.Counter
+
+	AMQPPublished     *prometheus.CounterVec
+	WorkflowsFinished *prometheus.CounterVec
+
+	OrdersCreated   prometheus.Counter
+	OrdersPaid      prometheus.Counter
+	OrdersCancelled prometheus.Counter
+	OrderValue      prometheus.Histogram
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		GRPCRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Handled gRPC requests by method and status code",
+		}, []string{"method", "code"}),
+		GRPCRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Duration of handled gRPC requests by method and status code",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		OutboundDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "outbound_request_duration_seconds",
+			Help:      "Duration of calls to other services by service, method and status code",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "method", "code"}),
+		CircuitBreakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "circuit_breaker_state",
+			Help:      "State of circuit breaker by service: 0 closed, 1 half-open, 2 open",
+		}, []string{"service"}),
+		UnitOfWorkDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "unit_of_work_duration_seconds",
+			Help:      "Duration of unit of work executions including commit",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		UnitOfWorkFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "unit_of_work_failures_total",
+			Help:      "Unit of work executions finished with error",
+		}),
+		AMQPPublished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "amqp_published_total",
+			Help:      "Published AMQP messages by routing key and result",
+		}, []string{"routing_key", "result"}),
+		WorkflowsFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "temporal_workflows_finished_total",
+			Help:      "Finished Temporal workflows by type and outcome",
+		}, []string{"workflow", "outcome"}),
+		OrdersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_created_total",
+			Help:      "Created orders",
+		}),
+		OrdersPaid: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_paid_total",
+			Help:      "Successfully paid orders",
+		}),
+		OrdersCancelled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_cancelled_total",
+			Help:      "Cancelled orders",
+		}),
+		OrderValue: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "order_value",
+			Help:      "Amount of paid orders",
+			Buckets:   prometheus.ExponentialBuckets(10, 2, 12),
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.GRPCRequests,
+		m.GRPCRequestDuration,
+		m.OutboundDuration,
+		m.CircuitBreakerState,
+		m.UnitOfWorkDuration,
+		m.UnitOfWorkFailures,
+		m.AMQPPublished,
+		m.WorkflowsFinished,
+		m.OrdersCreated,
+		m.OrdersPaid,
+		m.OrdersCancelled,
+		m.OrderValue,
+	)
+	return m
+}
+
+// SetCircuitBreakerState matches callback of client.NewCircuitBreaker
+func (m *Metrics) SetCircuitBreakerState(service string, state int) {
+	m.CircuitBreakerState.WithLabelValues(service).Set(float64(state))
+}
+
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
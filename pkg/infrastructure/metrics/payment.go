@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"order/pkg/application/service"
+)
+
+// NewPaymentService counts paid orders, since order is paid once payment service accepted payment
+func NewPaymentService(next service.PaymentService, metrics *Metrics) service.PaymentService {
+	return &paymentService{
+		paymentService: next,
+		metrics:        metrics,
+	}
+}
+
+type paymentService struct {
+	paymentService service.PaymentService
+	metrics        *Metrics
+}
+
+func (s *paymentService) ProcessPayment(ctx context.Context, userID, orderID uuid.UUID, amount float64) error {
+	err := s.paymentService.ProcessPayment(ctx, userID, orderID, amount)
+	if err != nil {
+		return err
+	}
+	s.metrics.OrdersPaid.Inc()
+	s.metrics.OrderValue.Observe(amount)
+	return nil
+}
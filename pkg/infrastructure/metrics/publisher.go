@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+
+	"order/pkg/application/service"
+	infraamqp "order/pkg/infrastructure/amqp"
+)
+
+func NewEventPublisher(publisher service.EventPublisher, metrics *Metrics) service.EventPublisher {
+	return &eventPublisher{
+		publisher: publisher,
+		metrics:   metrics,
+	}
+}
+
+type eventPublisher struct {
+	publisher service.EventPublisher
+	metrics   *Metrics
+}
+
+func (p *eventPublisher) PublishOrderCreated(ctx context.Context, event infraamqp.OrderCreatedEvent) error {
+	err := p.publisher.PublishOrderCreated(ctx, event)
+	p.metrics.AMQPPublished.WithLabelValues("order.created", result(err)).Inc()
+	return err
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
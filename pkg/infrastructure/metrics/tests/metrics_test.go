@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	infraamqp "order/pkg/infrastructure/amqp"
+	"order/pkg/infrastructure/metrics"
+)
+
+func TestUnitOfWorkMetrics(t *testing.T) {
+	t.Run("Order events are counted after commit", func(t *testing.T) {
+		m := metrics.NewMetrics()
+		uow := metrics.NewUnitOfWork(&mockUnitOfWork{}, m)
+
+		err := uow.Execute(context.Background(), func(provider service.RepositoryProvider) error {
+			repo := provider.OrderEventRepository()
+			for _, event := range []model.OrderEvent{
+				model.OrderCreated{OrderID: uuid.New()},
+				model.OrderStatusChanged{Status: model.Cancelled},
+				model.OrderStatusChanged{Status: model.Pending},
+			} {
+				err := repo.Append(context.Background(), &model.OrderEventRecord{Event: event})
+				if err != nil {
+					return err
+				}
+			}
+			require.Zero(t, testutil.ToFloat64(m.OrdersCreated))
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 1.0, testutil.ToFloat64(m.OrdersCreated))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.OrdersCancelled))
+		require.Equal(t, 1, testutil.CollectAndCount(m.UnitOfWorkDuration))
+		require.Zero(t, testutil.ToFloat64(m.UnitOfWorkFailures))
+	})
+
+	t.Run("Rolled back events are not counted", func(t *testing.T) {
+		m := metrics.NewMetrics()
+		uow := metrics.NewUnitOfWork(&mockUnitOfWork{}, m)
+
+		err := uow.Execute(context.Background(), func(provider service.RepositoryProvider) error {
+			err := provider.OrderEventRepository().Append(context.Background(), &model.OrderEventRecord{
+				Event: model.OrderCreated{OrderID: uuid.New()},
+			})
+			require.NoError(t, err)
+			return errors.New("rollback")
+		})
+		require.Error(t, err)
+
+		require.Zero(t, testutil.ToFloat64(m.OrdersCreated))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.UnitOfWorkFailures))
+	})
+}
+
+func TestEventPublisherMetrics(t *testing.T) {
+	m := metrics.NewMetrics()
+	publishErr := errors.New("connection closed")
+	publisher := metrics.NewEventPublisher(&mockEventPublisher{errs: []error{nil, publishErr}}, m)
+
+	require.NoError(t, publisher.PublishOrderCreated(context.Background(), infraamqp.OrderCreatedEvent{}))
+	require.ErrorIs(t, publisher.PublishOrderCreated(context.Background(), infraamqp.OrderCreatedEvent{}), publishErr)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.AMQPPublished.WithLabelValues("order.created", "ok")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.AMQPPublished.WithLabelValues("order.created", "error")))
+}
+
+type mockUnitOfWork struct {
+	hooks []service.PostCommitHook
+}
+
+func (m *mockUnitOfWork) Execute(ctx context.Context, f func(provider service.RepositoryProvider) error) error {
+	m.hooks = nil
+	err := f(&mockRepositoryProvider{})
+	if err != nil {
+		return err
+	}
+	// hook errors don't fail committed unit of work
+	for _, hook := range m.hooks {
+		_ = hook(ctx)
+	}
+	return nil
+}
+
+func (m *mockUnitOfWork) AfterCommit(_ context.Context, hook service.PostCommitHook) error {
+	m.hooks = append(m.hooks, hook)
+	return nil
+}
+
+type mockRepositoryProvider struct{}
+
+func (m *mockRepositoryProvider) OrderRepository() model.OrderRepository {
+	return nil
+}
+
+// OrderQuery is not used by tested code
+func (m *mockRepositoryProvider) OrderQuery() service.OrderQuery {
+	return nil
+}
+
+func (m *mockRepositoryProvider) OrderCountQuery() service.OrderCountQuery {
+	return nil
+}
+
+func (m *mockRepositoryProvider) OrderEventRepository() model.OrderEventRepository {
+	return &mockOrderEventRepository{}
+}
+
+type mockOrderEventRepository struct{}
+
+func (m *mockOrderEventRepository) Append(context.Context, *model.OrderEventRecord) error {
+	return nil
+}
+
+func (m *mockOrderEventRepository) FindByOrderID(context.Context, uuid.UUID) ([]model.OrderEventRecord, error) {
+	return nil, nil
+}
+
+type mockEventPublisher struct {
+	errs []error
+}
+
+func (m *mockEventPublisher) PublishOrderCreated(context.Context, infraamqp.OrderCreatedEvent) error {
+	err := m.errs[0]
+	m.errs = m.errs[1:]
+	return err
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+// NewUnitOfWork measures executions and counts order events once their transaction is committed
+func NewUnitOfWork(uow service.UnitOfWork, metrics *Metrics) service.UnitOfWork {
+	return &unitOfWork{
+		UnitOfWork: uow,
+		metrics:    metrics,
+	}
+}
+
+type unitOfWork struct {
+	service.UnitOfWork
+	metrics *Metrics
+}
+
+func (u *unitOfWork) Execute(ctx context.Context, f func(provider service.RepositoryProvider) error) error {
+	start := time.Now()
+	err := u.UnitOfWork.Execute(ctx, func(provider service.RepositoryProvider) error {
+		return f(&repositoryProvider{
+			RepositoryProvider: provider,
+			uow:                u,
+		})
+	})
+	u.metrics.UnitOfWorkDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		u.metrics.UnitOfWorkFailures.Inc()
+	}
+	return err
+}
+
+type repositoryProvider struct {
+	service.RepositoryProvider
+	uow *unitOfWork
+}
+
+func (p *repositoryProvider) OrderEventRepository() model.OrderEventRepository {
+	return &orderEventRepository{
+		OrderEventRepository: p.RepositoryProvider.OrderEventRepository(),
+		uow:                  p.uow,
+	}
+}
+
+type orderEventRepository struct {
+	model.OrderEventRepository
+	uow *unitOfWork
+}
+
+func (r *orderEventRepository) Append(ctx context.Context, record *model.OrderEventRecord) error {
+	err := r.OrderEventRepository.Append(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	event := record.Event
+	// metrics must not fail write, so event outside of unit of work is just not counted
+	_ = r.uow.AfterCommit(ctx, func(context.Context) error {
+		switch e := event.(type) {
+		case model.OrderCreated:
+			r.uow.metrics.OrdersCreated.Inc()
+		case model.OrderStatusChanged:
+			if e.Status == model.Cancelled {
+				r.uow.metrics.OrdersCancelled.Inc()
+			}
+		}
+		return nil
+	})
+	return nil
+}
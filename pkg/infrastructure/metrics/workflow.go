@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// NewWorkerInterceptor counts workflow outcomes, replayed executions are not counted twice
+func NewWorkerInterceptor(metrics *Metrics) interceptor.WorkerInterceptor {
+	return &workerInterceptor{
+		metrics: metrics,
+	}
+}
+
+type workerInterceptor struct {
+	interceptor.WorkerInterceptorBase
+	metrics *Metrics
+}
+
+func (w *workerInterceptor) InterceptWorkflow(
+	_ workflow.Context,
+	next interceptor.WorkflowInboundInterceptor,
+) interceptor.WorkflowInboundInterceptor {
+	i := &workflowInboundInterceptor{metrics: w.metrics}
+	i.Next = next
+	return i
+}
+
+type workflowInboundInterceptor struct {
+	interceptor.WorkflowInboundInterceptorBase
+	metrics *Metrics
+}
+
+func (w *workflowInboundInterceptor) ExecuteWorkflow(
+	ctx workflow.Context,
+	in *interceptor.ExecuteWorkflowInput,
+) (interface{}, error) {
+	result, err := w.Next.ExecuteWorkflow(ctx, in)
+	if !workflow.IsReplaying(ctx) {
+		w.metrics.WorkflowsFinished.WithLabelValues(workflow.GetInfo(ctx).WorkflowType.Name, workflowOutcome(err)).Inc()
+	}
+	return result, err
+}
+
+func workflowOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "completed"
+	case temporal.IsCanceledError(err):
+		return "canceled"
+	case workflow.IsContinueAsNewError(err):
+		return "continued_as_new"
+	default:
+		return "failed"
+	}
+}
@@ -0,0 +1,50 @@
+package data
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+type backfillOrdersTotalPrice struct{}
+
+func (m backfillOrdersTotalPrice) Name() string {
+	return "backfill_orders_total_price"
+}
+
+func (m backfillOrdersTotalPrice) Description() string {
+	return "Calculate orders total price from order items"
+}
+
+func (m backfillOrdersTotalPrice) Batch(ctx context.Context, client mysql.ClientContext, lastKey []byte, size int) (BatchResult, error) {
+	var orderIDs [][]byte
+	err := client.SelectContext(ctx, &orderIDs, `
+SELECT order_id FROM orders
+WHERE ? IS NULL OR order_id > ?
+ORDER BY order_id
+LIMIT ?
+FOR UPDATE
+`, lastKey, lastKey, size)
+	if err != nil {
+		return BatchResult{}, errors.WithStack(err)
+	}
+	if len(orderIDs) == 0 {
+		return BatchResult{LastKey: lastKey}, nil
+	}
+
+	first, last := orderIDs[0], orderIDs[len(orderIDs)-1]
+	_, err = client.ExecContext(ctx, `
+UPDATE orders
+SET total_price = (
+    SELECT COALESCE(SUM(order_items.price * order_items.quantity), 0)
+    FROM order_items
+    WHERE order_items.order_id = orders.order_id
+)
+WHERE order_id BETWEEN ? AND ?
+`, first, last)
+	if err != nil {
+		return BatchResult{}, errors.WithStack(err)
+	}
+	return BatchResult{LastKey: last, Rows: len(orderIDs)}, nil
+}
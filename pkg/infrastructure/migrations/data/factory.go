@@ -0,0 +1,33 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+)
+
+type ReleaseConnectionFunc func() error
+
+type Config struct {
+	// BatchSize is max rows changed in single transaction, 1000 if zero
+	BatchSize int
+	// BatchInterval is pause between batches to limit load on database
+	BatchInterval time.Duration
+}
+
+func NewDataMigrator(
+	ctx context.Context,
+	pool mysql.ConnectionPool,
+	logger logging.Logger,
+	config Config,
+) (Migrator, ReleaseConnectionFunc, error) {
+	conn, err := pool.TransactionalConnection(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := logger.WithField("migrator", "data")
+	return newMigrator(ctx, conn, l, config, migrations), conn.Close, nil
+}
@@ -0,0 +1,28 @@
+package data
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+)
+
+// Migration changes existing rows in batches ordered by primary key,
+// so it can be stopped at any batch and resumed from the last processed key
+type Migration interface {
+	Name() string
+	Description() string
+	// Batch processes at most size rows with primary key greater than lastKey, nil lastKey means start of table.
+	// Batch is executed in transaction together with storing progress
+	Batch(ctx context.Context, client mysql.ClientContext, lastKey []byte, size int) (BatchResult, error)
+}
+
+type BatchResult struct {
+	// LastKey is primary key of last processed row
+	LastKey []byte
+	// Rows is count of processed rows, migration is completed when it is less than batch size
+	Rows int
+}
+
+var migrations = []Migration{
+	backfillOrdersTotalPrice{},
+}
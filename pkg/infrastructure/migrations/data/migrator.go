@@ -0,0 +1,246 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+)
+
+const (
+	defaultBatchSize = 1000
+	// lockNamePrefix with migration name is used as advisory lock, so only one replica runs migration
+	lockNamePrefix = "data_migration_"
+)
+
+var (
+	ErrUnknownMigration = errors.New("unknown data migration")
+	ErrMigrationRunning = errors.New("data migration is already running")
+	ErrMigrationDone    = errors.New("data migration is completed")
+)
+
+type MigrationStatus struct {
+	Name          string
+	Description   string
+	State         string
+	ProcessedRows int64
+	UpdatedAt     time.Time
+}
+
+type Migrator interface {
+	// Run runs or resumes given migrations, all not paused migrations if names are empty
+	Run(names ...string) error
+	Status() ([]MigrationStatus, error)
+	// Pause stops running migration after current batch, paused migration is resumed only by explicit Run
+	Pause(name string) error
+}
+
+func newMigrator(
+	ctx context.Context,
+	conn mysql.TransactionalConnection,
+	logger logging.Logger,
+	config Config,
+	migrations []Migration,
+) Migrator {
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &migrator{
+		ctx:           ctx,
+		conn:          conn,
+		storage:       newStorage(conn),
+		logger:        logger,
+		batchSize:     batchSize,
+		batchInterval: config.BatchInterval,
+		migrations:    migrations,
+	}
+}
+
+type migrator struct {
+	ctx context.Context
+
+	conn          mysql.TransactionalConnection
+	storage       *storage
+	logger        logging.Logger
+	batchSize     int
+	batchInterval time.Duration
+
+	migrations []Migration
+}
+
+func (m *migrator) Run(names ...string) error {
+	migrations := m.migrations
+	if len(names) > 0 {
+		var err error
+		migrations, err = m.find(names)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := m.storage.Init(m.ctx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		err = m.run(migration, len(names) > 0)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *migrator) Status() ([]MigrationStatus, error) {
+	progresses, err := m.storage.FindAll(m.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		p, ok := progresses[migration.Name()]
+		if !ok {
+			p.State = statePending
+		}
+		statuses = append(statuses, MigrationStatus{
+			Name:          migration.Name(),
+			Description:   migration.Description(),
+			State:         string(p.State),
+			ProcessedRows: p.ProcessedRows,
+			UpdatedAt:     p.UpdatedAt,
+		})
+	}
+	return statuses, nil
+}
+
+func (m *migrator) Pause(name string) error {
+	_, err := m.find([]string{name})
+	if err != nil {
+		return err
+	}
+	err = m.storage.Init(m.ctx)
+	if err != nil {
+		return err
+	}
+
+	progresses, err := m.storage.FindAll(m.ctx)
+	if err != nil {
+		return err
+	}
+	if progresses[name].State == stateCompleted {
+		return fmt.Errorf("%w: %s", ErrMigrationDone, name)
+	}
+	err = m.storage.SetState(m.ctx, name, statePaused)
+	if err != nil {
+		return err
+	}
+	m.logger.Info(fmt.Sprintf("data migration '%s' paused", name))
+	return nil
+}
+
+func (m *migrator) run(migration Migration, resume bool) (err error) {
+	lock := mysql.NewLock(m.ctx, lockNamePrefix+migration.Name(), 0, m.conn)
+	err = lock.Lock()
+	if err != nil {
+		if errors.Is(err, mysql.ErrLockTimeout) {
+			return fmt.Errorf("%w: %s", ErrMigrationRunning, migration.Name())
+		}
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Unlock())
+	}()
+
+	if resume {
+		err = m.storage.SetState(m.ctx, migration.Name(), stateRunning)
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		done, err := m.batch(migration)
+		if err != nil || done {
+			return err
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return m.ctx.Err()
+		case <-time.After(m.batchInterval):
+		}
+	}
+}
+
+// batch processes next batch and returns true when migration must not continue
+func (m *migrator) batch(migration Migration) (done bool, err error) {
+	tx, err := m.conn.BeginTransaction(m.ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	p, err := m.storage.FindForUpdate(m.ctx, tx, migration.Name())
+	if err != nil {
+		return false, err
+	}
+	switch p.State {
+	case stateCompleted:
+		m.logger.Info(fmt.Sprintf("data migration '%s' is already completed", migration.Name()))
+		return true, tx.Rollback()
+	case statePaused:
+		m.logger.Info(fmt.Sprintf("data migration '%s' is paused after %d rows", migration.Name(), p.ProcessedRows))
+		return true, tx.Rollback()
+	default:
+	}
+
+	result, err := migration.Batch(m.ctx, tx, p.LastKey, m.batchSize)
+	if err != nil {
+		return false, err
+	}
+
+	p.State = stateRunning
+	if result.Rows < m.batchSize {
+		p.State = stateCompleted
+	}
+	p.LastKey = result.LastKey
+	p.ProcessedRows += int64(result.Rows)
+	err = m.storage.Store(m.ctx, tx, p)
+	if err != nil {
+		return false, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	if p.State == stateCompleted {
+		m.logger.Info(fmt.Sprintf("data migration '%s' completed, %d rows processed", migration.Name(), p.ProcessedRows))
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *migrator) find(names []string) ([]Migration, error) {
+	result := make([]Migration, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(m.migrations, func(migration Migration) bool {
+			return migration.Name() == name
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMigration, name)
+		}
+		result = append(result, m.migrations[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+const dataMigrationsTable = "data_migrations"
+
+type migrationState string
+
+const (
+	statePending   migrationState = "pending"
+	stateRunning   migrationState = "running"
+	statePaused    migrationState = "paused"
+	stateCompleted migrationState = "completed"
+)
+
+type progress struct {
+	Name          string         `db:"name"`
+	State         migrationState `db:"state"`
+	LastKey       []byte         `db:"last_key"`
+	ProcessedRows int64          `db:"processed_rows"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func newStorage(client mysql.ClientContext) *storage {
+	return &storage{
+		client: client,
+	}
+}
+
+type storage struct {
+	client mysql.ClientContext
+}
+
+func (s *storage) Init(ctx context.Context) error {
+	_, err := s.client.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS `+dataMigrationsTable+`
+(
+    name           VARCHAR(255)   NOT NULL,
+    state          VARCHAR(16)    NOT NULL,
+    last_key       VARBINARY(255) NULL,
+    processed_rows BIGINT         NOT NULL,
+    updated_at     DATETIME       NOT NULL,
+    PRIMARY KEY (name)
+)
+    ENGINE = InnoDB
+    CHARACTER SET = utf8mb4
+    COLLATE utf8mb4_unicode_ci
+`)
+	return errors.WithStack(err)
+}
+
+// FindForUpdate locks progress row until end of batch transaction, so pause waits for running batch.
+// Pending progress is returned for migration which was never started
+func (s *storage) FindForUpdate(ctx context.Context, client mysql.ClientContext, name string) (progress, error) {
+	var p progress
+	err := client.GetContext(ctx, &p,
+		`SELECT name, state, last_key, processed_rows, updated_at FROM `+dataMigrationsTable+` WHERE name = ? FOR UPDATE`,
+		name,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return progress{Name: name, State: statePending}, nil
+	}
+	return p, errors.WithStack(err)
+}
+
+func (s *storage) FindAll(ctx context.Context) (map[string]progress, error) {
+	exists, err := s.exists(ctx)
+	if err != nil || !exists {
+		return map[string]progress{}, err
+	}
+
+	var progresses []progress
+	err = s.client.SelectContext(ctx, &progresses,
+		`SELECT name, state, last_key, processed_rows, updated_at FROM `+dataMigrationsTable,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make(map[string]progress, len(progresses))
+	for _, p := range progresses {
+		result[p.Name] = p
+	}
+	return result, nil
+}
+
+// Store is called with batch transaction client, so progress is saved atomically with batch changes
+func (s *storage) Store(ctx context.Context, client mysql.ClientContext, p progress) error {
+	_, err := client.ExecContext(ctx, `
+INSERT INTO `+dataMigrationsTable+` (name, state, last_key, processed_rows, updated_at) VALUES (?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+state = VALUES(state),
+last_key = VALUES(last_key),
+processed_rows = VALUES(processed_rows),
+updated_at = VALUES(updated_at)
+`,
+		p.Name,
+		p.State,
+		p.LastKey,
+		p.ProcessedRows,
+		time.Now(),
+	)
+	return errors.WithStack(err)
+}
+
+// SetState changes state without touching progress, so it is safe to call while migration is running.
+// Completed migration stays completed
+func (s *storage) SetState(ctx context.Context, name string, state migrationState) error {
+	_, err := s.client.ExecContext(ctx, `
+INSERT INTO `+dataMigrationsTable+` (name, state, last_key, processed_rows, updated_at) VALUES (?, ?, NULL, 0, ?)
+ON DUPLICATE KEY UPDATE
+state = IF(state = '`+string(stateCompleted)+`', state, VALUES(state)),
+updated_at = VALUES(updated_at)
+`,
+		name,
+		state,
+		time.Now(),
+	)
+	return errors.WithStack(err)
+}
+
+func (s *storage) exists(ctx context.Context) (bool, error) {
+	var exists bool
+	err := s.client.GetContext(ctx, &exists, `
+SELECT EXISTS(
+    SELECT * FROM information_schema.tables
+    WHERE table_schema = DATABASE()
+    AND table_name = ?
+)
+`, dataMigrationsTable)
+	return exists, errors.WithStack(err)
+}
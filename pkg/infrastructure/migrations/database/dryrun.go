@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+)
+
+// dryRunClient reads from database as usual but prints statements changing it instead of executing them
+type dryRunClient struct {
+	mysql.ClientContext
+	out io.Writer
+}
+
+func (c *dryRunClient) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	_, err := fmt.Fprintf(c.out, "%s;\n", strings.TrimSpace(query))
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		_, err = fmt.Fprintf(c.out, "-- args: %v\n", args)
+	}
+	return driver.RowsAffected(0), err
+}
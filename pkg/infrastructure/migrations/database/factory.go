@@ -3,20 +3,40 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
-	libmigrator "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+
+	sqlmigrations "order/data/mysql/migrations"
+)
+
+var (
+	ErrDuplicateVersion = errors.New("duplicate migration version")
+	// ErrIncompleteMigration means SQL migration has only one of up and down files
+	ErrIncompleteMigration = errors.New("incomplete migration")
 )
 
-type MigrationBuilderFunc func(client mysql.ClientContext) libmigrator.Migration
+type MigrationBuilderFunc func(client mysql.ClientContext) Migration
 type ReleaseConnectionFunc func() error
 
+type Config struct {
+	// DryRunOutput receives SQL of changing statements instead of executing them, migrations are executed if nil
+	DryRunOutput io.Writer
+	// LockTimeout is how long to wait for migrations run by other replica, one minute if zero
+	LockTimeout time.Duration
+}
+
 func NewDatabaseMigrator(
 	ctx context.Context,
 	pool mysql.ConnectionPool,
 	logger logging.Logger,
-) (migrator libmigrator.Migrator, release ReleaseConnectionFunc, err error) {
+	config Config,
+) (migrator Migrator, release ReleaseConnectionFunc, err error) {
 	conn, err2 := pool.TransactionalConnection(ctx)
 	if err2 != nil {
 		return nil, nil, err2
@@ -27,21 +47,51 @@ func NewDatabaseMigrator(
 		}
 	}()
 
-	l := logger.WithField("migrator", "database")
-	factory := libmigrator.NewMigratorFactory("database", conn, l)
-
-	migrations := make([]libmigrator.Migration, 0, len(builderFunctions))
-	for _, builder := range builderFunctions {
-		migrations = append(migrations, builder(conn))
+	var client mysql.ClientContext = conn
+	if config.DryRunOutput != nil {
+		client = &dryRunClient{ClientContext: conn, out: config.DryRunOutput}
 	}
 
-	migrator, err = factory.NewMigrator(ctx, migrations...)
+	l := logger.WithField("migrator", "database")
+
+	migrations, err := LoadMigrations(client, sqlmigrations.FS)
 	if err != nil {
 		return nil, nil, err
 	}
+	if len(migrations) == 0 {
+		return nil, nil, errors.New("migrations must not be empty")
+	}
+
+	migrator = newMigrator(ctx, client, l, config, migrations)
 	return migrator, conn.Close, nil
 }
 
+// LoadMigrations merges Go migrations with SQL migrations found in sqlFS by version
+func LoadMigrations(client mysql.ClientContext, sqlFS fs.FS) ([]Migration, error) {
+	sqlBuilders, err := sqlMigrationBuilders(sqlFS)
+	if err != nil {
+		return nil, err
+	}
+
+	builders := slices.Concat(builderFunctions, sqlBuilders)
+	migrations := make([]Migration, 0, len(builders))
+	descriptions := make(map[int64]string, len(builders))
+	for _, builder := range builders {
+		migration := builder(client)
+		if description, ok := descriptions[migration.Version()]; ok {
+			return nil, fmt.Errorf(
+				"%w: %v is used by %q and %q",
+				ErrDuplicateVersion, migration.Version(), description, migration.Description(),
+			)
+		}
+		descriptions[migration.Version()] = migration.Description()
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
 var builderFunctions = []MigrationBuilderFunc{
 	NewVersion1732266003,
+	NewVersion1792400403,
+	NewVersion1792486803,
 }
@@ -0,0 +1,265 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	libmigrator "gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+)
+
+const (
+	// migrationLockName is shared with golib migrator, so both can't run simultaneously
+	migrationLockName = "migration"
+	// defaultLockTimeout is enough for other replica to finish usual migrations
+	defaultLockTimeout = time.Minute
+)
+
+var (
+	ErrUnknownVersion = errors.New("unknown migration version")
+	ErrSchemaBehind   = errors.New("database schema is behind")
+)
+
+type Migration interface {
+	libmigrator.Migration
+	Down(ctx context.Context) error
+}
+
+type MigrationStatus struct {
+	Version     int64
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// SchemaVersion compares applied migrations with migrations known to binary
+type SchemaVersion struct {
+	Current  int64
+	Expected int64
+	Pending  []int64
+}
+
+type Migrator interface {
+	// Migrate applies all pending migrations
+	Migrate() error
+	// Down reverts last applied migration
+	Down() error
+	// Goto applies or reverts migrations to make version the last applied, zero version reverts all migrations
+	Goto(version int64) error
+	Status() ([]MigrationStatus, error)
+	// SchemaVersion returns ErrSchemaBehind if some known migrations are not applied
+	SchemaVersion() (SchemaVersion, error)
+}
+
+func newMigrator(
+	ctx context.Context,
+	client mysql.ClientContext,
+	logger logging.Logger,
+	config Config,
+	migrations []Migration,
+) Migrator {
+	lockTimeout := config.LockTimeout
+	if lockTimeout == 0 {
+		lockTimeout = defaultLockTimeout
+	}
+	slices.SortFunc(migrations, func(l, r Migration) int {
+		return cmp.Compare(l.Version(), r.Version())
+	})
+	return &migrator{
+		ctx:          ctx,
+		storage:      newStorage(client),
+		lock:         mysql.NewLock(ctx, migrationLockName, lockTimeout, client),
+		logger:       logger,
+		dryRunOutput: config.DryRunOutput,
+		migrations:   migrations,
+	}
+}
+
+type migrator struct {
+	ctx context.Context
+
+	storage      *storage
+	lock         mysql.Lock
+	logger       logging.Logger
+	dryRunOutput io.Writer
+
+	migrations []Migration
+}
+
+func (m *migrator) Migrate() error {
+	return m.withLock(func(applied map[int64]time.Time) error {
+		lastVersion := lastAppliedVersion(applied)
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version()]; ok {
+				continue
+			}
+			if migration.Version() < lastVersion {
+				return fmt.Errorf("migration version %v less then last applied %v", migration.Version(), lastVersion)
+			}
+			err := m.up(migration)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *migrator) Down() error {
+	return m.withLock(func(applied map[int64]time.Time) error {
+		for _, migration := range slices.Backward(m.migrations) {
+			if _, ok := applied[migration.Version()]; ok {
+				return m.down(migration)
+			}
+		}
+		m.logger.Info("no applied migrations to revert")
+		return nil
+	})
+}
+
+func (m *migrator) Goto(version int64) error {
+	if version != 0 && !slices.ContainsFunc(m.migrations, func(migration Migration) bool {
+		return migration.Version() == version
+	}) {
+		return fmt.Errorf("%w: %v", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(func(applied map[int64]time.Time) error {
+		for _, migration := range slices.Backward(m.migrations) {
+			if _, ok := applied[migration.Version()]; !ok || migration.Version() <= version {
+				continue
+			}
+			err := m.down(migration)
+			if err != nil {
+				return err
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version()]; ok || migration.Version() > version {
+				continue
+			}
+			err := m.up(migration)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.storage.AppliedVersions(m.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version()]
+		statuses = append(statuses, MigrationStatus{
+			Version:     migration.Version(),
+			Description: migration.Description(),
+			Applied:     ok,
+			AppliedAt:   appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+func (m *migrator) SchemaVersion() (SchemaVersion, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+
+	var version SchemaVersion
+	for _, status := range statuses {
+		version.Expected = max(version.Expected, status.Version)
+		if status.Applied {
+			version.Current = max(version.Current, status.Version)
+		} else {
+			version.Pending = append(version.Pending, status.Version)
+		}
+	}
+	if len(version.Pending) > 0 {
+		return version, fmt.Errorf("%w: current version %v, pending migrations %v", ErrSchemaBehind, version.Current, version.Pending)
+	}
+	return version, nil
+}
+
+func (m *migrator) withLock(f func(applied map[int64]time.Time) error) (err error) {
+	err = m.lock.Lock()
+	if err != nil {
+		return err
+	}
+	// deferred unlock also runs when migration panics, panic is not recovered so it keeps its stack
+	defer func() {
+		err = errors.Join(err, m.lock.Unlock())
+	}()
+
+	err = m.storage.Init(m.ctx)
+	if err != nil {
+		return err
+	}
+	applied, err := m.storage.AppliedVersions(m.ctx)
+	if err != nil {
+		return err
+	}
+	return f(applied)
+}
+
+func (m *migrator) up(migration Migration) error {
+	err := m.printDryRunHeader("up", migration)
+	if err != nil {
+		return err
+	}
+	err = migration.Up(m.ctx)
+	if err != nil {
+		return err
+	}
+	err = m.storage.Store(m.ctx, migration)
+	if err != nil {
+		return err
+	}
+	m.logger.Info(fmt.Sprintf("migration '%v' successfully applied", migration.Version()))
+	return nil
+}
+
+func (m *migrator) down(migration Migration) error {
+	err := m.printDryRunHeader("down", migration)
+	if err != nil {
+		return err
+	}
+	err = migration.Down(m.ctx)
+	if err != nil {
+		return err
+	}
+	err = m.storage.Remove(m.ctx, migration)
+	if err != nil {
+		return err
+	}
+	m.logger.Info(fmt.Sprintf("migration '%v' successfully reverted", migration.Version()))
+	return nil
+}
+
+func (m *migrator) printDryRunHeader(direction string, migration Migration) error {
+	if m.dryRunOutput == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(m.dryRunOutput, "\n-- %s %v: %s\n", direction, migration.Version(), migration.Description())
+	return err
+}
+
+func lastAppliedVersion(applied map[int64]time.Time) int64 {
+	var lastVersion int64
+	for version := range applied {
+		lastVersion = max(lastVersion, version)
+	}
+	return lastVersion
+}
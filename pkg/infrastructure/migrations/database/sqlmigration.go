@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+var sqlMigrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// statementSeparator splits file into statements by semicolon at the end of line,
+// since connection doesn't allow multiple statements in single query
+var statementSeparator = regexp.MustCompile(`;\s*(\n|$)`)
+
+type sqlMigrationFiles struct {
+	version     int64
+	description string
+	up          *string
+	down        *string
+}
+
+// sqlMigrationBuilders discovers SQL migrations in root of fsys, every version must have both up and down files
+func sqlMigrationBuilders(fsys fs.FS) ([]MigrationBuilderFunc, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	files := map[int64]*sqlMigrationFiles{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		matches := sqlMigrationFileName.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, errors.Errorf("invalid SQL migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		f, ok := files[version]
+		if !ok {
+			f = &sqlMigrationFiles{version: version, description: matches[2]}
+			files[version] = f
+		}
+		if f.description != matches[2] {
+			return nil, errors.Wrapf(ErrDuplicateVersion, "SQL migrations %q and %q have version %d", f.description, matches[2], version)
+		}
+
+		body := string(content)
+		switch matches[3] {
+		case "up":
+			f.up = &body
+		case "down":
+			f.down = &body
+		}
+	}
+
+	builders := make([]MigrationBuilderFunc, 0, len(files))
+	for _, version := range slices.Sorted(maps.Keys(files)) {
+		f := files[version]
+		if f.up == nil || f.down == nil {
+			return nil, errors.Wrapf(ErrIncompleteMigration, "SQL migration %d_%s must have both up and down files", f.version, f.description)
+		}
+		up, down := splitStatements(*f.up), splitStatements(*f.down)
+		description := strings.ReplaceAll(f.description, "_", " ")
+		builders = append(builders, func(client mysql.ClientContext) Migration {
+			return &sqlMigration{
+				version:     version,
+				description: description,
+				up:          up,
+				down:        down,
+				client:      client,
+			}
+		})
+	}
+	return builders, nil
+}
+
+type sqlMigration struct {
+	version     int64
+	description string
+	up          []string
+	down        []string
+	client      mysql.ClientContext
+}
+
+func (m sqlMigration) Version() int64 {
+	return m.version
+}
+
+func (m sqlMigration) Description() string {
+	return fmt.Sprintf("%s (SQL)", m.description)
+}
+
+func (m sqlMigration) Up(ctx context.Context) error {
+	return execStatements(ctx, m.client, m.up...)
+}
+
+func (m sqlMigration) Down(ctx context.Context) error {
+	return execStatements(ctx, m.client, m.down...)
+}
+
+func splitStatements(content string) []string {
+	var statements []string
+	for _, statement := range statementSeparator.Split(content, -1) {
+		statement = strings.TrimSpace(statement)
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
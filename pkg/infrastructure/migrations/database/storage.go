@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+// migrationsTable is shared with golib migrator to keep already applied migrations
+const migrationsTable = "database_migrations"
+
+func newStorage(client mysql.ClientContext) *storage {
+	return &storage{
+		client: client,
+	}
+}
+
+type storage struct {
+	client mysql.ClientContext
+}
+
+func (s *storage) Init(ctx context.Context) error {
+	exists, err := s.exists(ctx)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = s.client.ExecContext(ctx, `
+CREATE TABLE `+migrationsTable+`
+(
+    version     BIGINT   NOT NULL,
+    description TEXT     NOT NULL,
+    applied_at  DATETIME NOT NULL,
+    PRIMARY KEY (version)
+)
+    ENGINE = InnoDB
+    CHARACTER SET = utf8mb4
+    COLLATE utf8mb4_unicode_ci
+`)
+	return errors.WithStack(err)
+}
+
+// AppliedVersions returns applied migrations versions with time of applying
+func (s *storage) AppliedVersions(ctx context.Context) (map[int64]time.Time, error) {
+	exists, err := s.exists(ctx)
+	if err != nil || !exists {
+		return map[int64]time.Time{}, err
+	}
+
+	var versionsData []struct {
+		Version   int64     `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	err = s.client.SelectContext(ctx, &versionsData, `SELECT version, applied_at FROM `+migrationsTable)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	versions := make(map[int64]time.Time, len(versionsData))
+	for _, versionData := range versionsData {
+		versions[versionData.Version] = versionData.AppliedAt
+	}
+	return versions, nil
+}
+
+func (s *storage) Store(ctx context.Context, migration Migration) error {
+	_, err := s.client.ExecContext(ctx,
+		`INSERT INTO `+migrationsTable+` (version, description, applied_at) VALUES (?, ?, ?)`,
+		migration.Version(),
+		migration.Description(),
+		time.Now(),
+	)
+	return errors.WithStack(err)
+}
+
+func (s *storage) Remove(ctx context.Context, migration Migration) error {
+	_, err := s.client.ExecContext(ctx, `DELETE FROM `+migrationsTable+` WHERE version = ?`, migration.Version())
+	return errors.WithStack(err)
+}
+
+func (s *storage) exists(ctx context.Context) (bool, error) {
+	var exists bool
+	err := s.client.GetContext(ctx, &exists, `
+SELECT EXISTS(
+    SELECT * FROM information_schema.tables
+    WHERE table_schema = DATABASE()
+    AND table_name = ?
+)
+`, migrationsTable)
+	return exists, errors.WithStack(err)
+}
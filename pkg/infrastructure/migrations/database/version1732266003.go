@@ -3,12 +3,11 @@ package database
 import (
 	"context"
 
-	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/migrator"
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 	"github.com/pkg/errors"
 )
 
-func NewVersion1732266003(client mysql.ClientContext) migrator.Migration {
+func NewVersion1732266003(client mysql.ClientContext) Migration {
 	return &version1732266003{
 		client: client,
 	}
@@ -64,3 +63,10 @@ CREATE TABLE order_items
 `)
 	return errors.WithStack(err)
 }
+
+func (v version1732266003) Down(ctx context.Context) error {
+	return execStatements(ctx, v.client,
+		`DROP TABLE order_items`,
+		`DROP TABLE orders`,
+	)
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792400403(client mysql.ClientContext) Migration {
+	return &version1792400403{
+		client: client,
+	}
+}
+
+type version1792400403 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792400403) Version() int64 {
+	return 1792400403
+}
+
+func (v version1792400403) Description() string {
+	return "Create 'order_events' table"
+}
+
+func (v version1792400403) Up(ctx context.Context) error {
+	_, err := v.client.ExecContext(ctx, `
+CREATE TABLE order_events
+(
+    event_id    BIGINT       NOT NULL AUTO_INCREMENT,
+    order_id    VARCHAR(64)  NOT NULL,
+    event_type  VARCHAR(64)  NOT NULL,
+    payload     JSON         NOT NULL,
+    actor       VARCHAR(255) NOT NULL,
+    source      VARCHAR(255) NOT NULL,
+    occurred_at DATETIME(6)  NOT NULL,
+    PRIMARY KEY (event_id),
+    INDEX idx_order_id_event_id (order_id, event_id)
+)
+    ENGINE = InnoDB
+    CHARACTER SET = utf8mb4
+    COLLATE utf8mb4_unicode_ci
+`)
+	return errors.WithStack(err)
+}
+
+func (v version1792400403) Down(ctx context.Context) error {
+	return execStatements(ctx, v.client,
+		`DROP TABLE order_events`,
+	)
+}
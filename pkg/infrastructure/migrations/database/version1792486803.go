@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/pkg/errors"
+)
+
+func NewVersion1792486803(client mysql.ClientContext) Migration {
+	return &version1792486803{
+		client: client,
+	}
+}
+
+type version1792486803 struct {
+	client mysql.ClientContext
+}
+
+func (v version1792486803) Version() int64 {
+	return 1792486803
+}
+
+func (v version1792486803) Description() string {
+	return "Convert order IDs to BINARY(16), add foreign keys, listing indexes and checks"
+}
+
+func (v version1792486803) Up(ctx context.Context) error {
+	return execStatements(ctx, v.client,
+		// Items without order can't satisfy foreign key, so they are dropped before conversion
+		`
+DELETE order_items
+FROM order_items
+    LEFT JOIN orders ON orders.order_id = order_items.order_id
+WHERE orders.order_id IS NULL
+`,
+		`
+ALTER TABLE orders
+    ADD COLUMN order_id_bin BINARY(16) NULL,
+    ADD COLUMN user_id_bin  BINARY(16) NULL
+`,
+		`UPDATE orders SET order_id_bin = UUID_TO_BIN(order_id), user_id_bin = UUID_TO_BIN(user_id)`,
+		`
+ALTER TABLE orders
+    DROP PRIMARY KEY,
+    DROP INDEX idx_user_id,
+    DROP COLUMN order_id,
+    DROP COLUMN user_id,
+    CHANGE COLUMN order_id_bin order_id BINARY(16) NOT NULL FIRST,
+    CHANGE COLUMN user_id_bin user_id BINARY(16) NOT NULL AFTER order_id,
+    ADD PRIMARY KEY (order_id),
+    ADD INDEX idx_user_id_created_at (user_id, created_at),
+    ADD INDEX idx_status (status),
+    ADD CONSTRAINT chk_orders_total_price CHECK (total_price >= 0)
+`,
+		`
+ALTER TABLE order_items
+    ADD COLUMN item_id_bin    BINARY(16) NULL,
+    ADD COLUMN order_id_bin   BINARY(16) NULL,
+    ADD COLUMN product_id_bin BINARY(16) NULL
+`,
+		`
+UPDATE order_items
+SET item_id_bin    = UUID_TO_BIN(item_id),
+    order_id_bin   = UUID_TO_BIN(order_id),
+    product_id_bin = UUID_TO_BIN(product_id)
+`,
+		`
+ALTER TABLE order_items
+    DROP PRIMARY KEY,
+    DROP INDEX idx_order_id,
+    DROP COLUMN item_id,
+    DROP COLUMN order_id,
+    DROP COLUMN product_id,
+    CHANGE COLUMN item_id_bin item_id BINARY(16) NOT NULL FIRST,
+    CHANGE COLUMN order_id_bin order_id BINARY(16) NOT NULL AFTER item_id,
+    CHANGE COLUMN product_id_bin product_id BINARY(16) NOT NULL AFTER order_id,
+    ADD PRIMARY KEY (item_id),
+    ADD INDEX idx_order_id (order_id),
+    ADD INDEX idx_product_id (product_id),
+    ADD CONSTRAINT fk_order_items_order_id FOREIGN KEY (order_id) REFERENCES orders (order_id) ON DELETE CASCADE,
+    ADD CONSTRAINT chk_order_items_quantity CHECK (quantity > 0),
+    ADD CONSTRAINT chk_order_items_price CHECK (price >= 0)
+`,
+		// order_events keeps history of deleted orders too, so it has no foreign key
+		`ALTER TABLE order_events ADD COLUMN order_id_bin BINARY(16) NULL`,
+		`UPDATE order_events SET order_id_bin = UUID_TO_BIN(order_id)`,
+		`
+ALTER TABLE order_events
+    DROP INDEX idx_order_id_event_id,
+    DROP COLUMN order_id,
+    CHANGE COLUMN order_id_bin order_id BINARY(16) NOT NULL AFTER event_id,
+    ADD INDEX idx_order_id_event_id (order_id, event_id)
+`,
+	)
+}
+
+func (v version1792486803) Down(ctx context.Context) error {
+	return execStatements(ctx, v.client,
+		`
+ALTER TABLE order_events
+    ADD COLUMN order_id_str VARCHAR(64) NULL
+`,
+		`UPDATE order_events SET order_id_str = BIN_TO_UUID(order_id)`,
+		`
+ALTER TABLE order_events
+    DROP INDEX idx_order_id_event_id,
+    DROP COLUMN order_id,
+    CHANGE COLUMN order_id_str order_id VARCHAR(64) NOT NULL AFTER event_id,
+    ADD INDEX idx_order_id_event_id (order_id, event_id)
+`,
+		`
+ALTER TABLE order_items
+    DROP FOREIGN KEY fk_order_items_order_id,
+    DROP CHECK chk_order_items_quantity,
+    DROP CHECK chk_order_items_price,
+    ADD COLUMN item_id_str    VARCHAR(64) NULL,
+    ADD COLUMN order_id_str   VARCHAR(64) NULL,
+    ADD COLUMN product_id_str VARCHAR(64) NULL
+`,
+		`
+UPDATE order_items
+SET item_id_str    = BIN_TO_UUID(item_id),
+    order_id_str   = BIN_TO_UUID(order_id),
+    product_id_str = BIN_TO_UUID(product_id)
+`,
+		`
+ALTER TABLE order_items
+    DROP PRIMARY KEY,
+    DROP INDEX idx_order_id,
+    DROP INDEX idx_product_id,
+    DROP COLUMN item_id,
+    DROP COLUMN order_id,
+    DROP COLUMN product_id,
+    CHANGE COLUMN item_id_str item_id VARCHAR(64) NOT NULL FIRST,
+    CHANGE COLUMN order_id_str order_id VARCHAR(64) NOT NULL AFTER item_id,
+    CHANGE COLUMN product_id_str product_id VARCHAR(64) NOT NULL AFTER order_id,
+    ADD PRIMARY KEY (item_id),
+    ADD INDEX idx_order_id (order_id)
+`,
+		`
+ALTER TABLE orders
+    DROP CHECK chk_orders_total_price,
+    ADD COLUMN order_id_str VARCHAR(64) NULL,
+    ADD COLUMN user_id_str  VARCHAR(64) NULL
+`,
+		`UPDATE orders SET order_id_str = BIN_TO_UUID(order_id), user_id_str = BIN_TO_UUID(user_id)`,
+		`
+ALTER TABLE orders
+    DROP PRIMARY KEY,
+    DROP INDEX idx_user_id_created_at,
+    DROP INDEX idx_status,
+    DROP COLUMN order_id,
+    DROP COLUMN user_id,
+    CHANGE COLUMN order_id_str order_id VARCHAR(64) NOT NULL FIRST,
+    CHANGE COLUMN user_id_str user_id VARCHAR(64) NOT NULL AFTER order_id,
+    ADD PRIMARY KEY (order_id),
+    ADD INDEX idx_user_id (user_id)
+`,
+	)
+}
+
+func execStatements(ctx context.Context, client mysql.ClientContext, statements ...string) error {
+	for _, statement := range statements {
+		_, err := client.ExecContext(ctx, statement)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	sqlmigrations "order/data/mysql/migrations"
+	"order/pkg/infrastructure/migrations/database"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("Embedded SQL migrations are merged with Go migrations", func(t *testing.T) {
+		migrations, err := database.LoadMigrations(&mockClient{}, sqlmigrations.FS)
+		require.NoError(t, err)
+
+		versions := make([]int64, 0, len(migrations))
+		for _, migration := range migrations {
+			versions = append(versions, migration.Version())
+		}
+		require.Contains(t, versions, int64(1732266003))
+		require.Contains(t, versions, int64(1792659603))
+	})
+
+	t.Run("SQL migration statements are executed one by one", func(t *testing.T) {
+		client := &mockClient{}
+		migrations, err := database.LoadMigrations(client, fstest.MapFS{
+			"1800000000_create_tables.up.sql":   {Data: []byte("CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n")},
+			"1800000000_create_tables.down.sql": {Data: []byte("DROP TABLE b;\nDROP TABLE a;")},
+		})
+		require.NoError(t, err)
+
+		migration := migrations[len(migrations)-1]
+		require.Equal(t, int64(1800000000), migration.Version())
+		require.Equal(t, "create tables (SQL)", migration.Description())
+
+		require.NoError(t, migration.Up(context.Background()))
+		require.Equal(t, []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"}, client.queries)
+
+		client.queries = nil
+		require.NoError(t, migration.Down(context.Background()))
+		require.Equal(t, []string{"DROP TABLE b", "DROP TABLE a"}, client.queries)
+	})
+
+	t.Run("Duplicate version of Go and SQL migration is rejected", func(t *testing.T) {
+		_, err := database.LoadMigrations(&mockClient{}, fstest.MapFS{
+			"1732266003_create_orders.up.sql":   {Data: []byte("SELECT 1;")},
+			"1732266003_create_orders.down.sql": {Data: []byte("SELECT 1;")},
+		})
+		require.ErrorIs(t, err, database.ErrDuplicateVersion)
+	})
+
+	t.Run("Duplicate version of SQL migrations is rejected", func(t *testing.T) {
+		_, err := database.LoadMigrations(&mockClient{}, fstest.MapFS{
+			"1800000000_first.up.sql":    {Data: []byte("SELECT 1;")},
+			"1800000000_first.down.sql":  {Data: []byte("SELECT 1;")},
+			"1800000000_second.up.sql":   {Data: []byte("SELECT 1;")},
+			"1800000000_second.down.sql": {Data: []byte("SELECT 1;")},
+		})
+		require.ErrorIs(t, err, database.ErrDuplicateVersion)
+	})
+
+	t.Run("SQL migration without down file is rejected", func(t *testing.T) {
+		_, err := database.LoadMigrations(&mockClient{}, fstest.MapFS{
+			"1800000000_create_tables.up.sql": {Data: []byte("SELECT 1;")},
+		})
+		require.ErrorIs(t, err, database.ErrIncompleteMigration)
+	})
+}
+
+type mockClient struct {
+	queries []string
+}
+
+func (m *mockClient) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (m *mockClient) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (m *mockClient) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	m.queries = append(m.queries, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (m *mockClient) SelectContext(context.Context, interface{}, string, ...interface{}) error {
+	return sql.ErrConnDone
+}
+
+func (m *mockClient) GetContext(context.Context, interface{}, string, ...interface{}) error {
+	return sql.ErrConnDone
+}
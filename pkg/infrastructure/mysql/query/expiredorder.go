@@ -0,0 +1,38 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+// NewExpiredOrderQuery finds expired orders using idx_status_created_at index
+func NewExpiredOrderQuery(client mysql.ClientContext) service.ExpiredOrderQuery {
+	return &expiredOrderQuery{
+		client: client,
+	}
+}
+
+type expiredOrderQuery struct {
+	client mysql.ClientContext
+}
+
+func (q *expiredOrderQuery) FindExpiredOrders(ctx context.Context, createdBefore time.Time, limit int) ([]uuid.UUID, error) {
+	var orderIDs []uuid.UUID
+	err := q.client.SelectContext(
+		ctx,
+		&orderIDs,
+		`SELECT order_id FROM orders WHERE status IN (?, ?) AND created_at < ? ORDER BY created_at LIMIT ?`,
+		model.Open,
+		model.Pending,
+		createdBefore,
+		limit,
+	)
+	return orderIDs, errors.WithStack(err)
+}
@@ -8,39 +8,22 @@ import (
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
-)
-
-type OrderQueryService interface {
-	GetOrder(ctx context.Context, orderID uuid.UUID) (*Order, error)
-}
-
-type Order struct {
-	OrderID    uuid.UUID
-	UserID     uuid.UUID
-	Status     string
-	TotalPrice float64
-	Items      []OrderItem
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-}
 
-type OrderItem struct {
-	ProductID uuid.UUID
-	Quantity  int
-	Price     float64
-}
+	appmodel "order/pkg/application/model"
+	"order/pkg/application/service"
+)
 
-func NewOrderQueryService(client mysql.ClientContext) OrderQueryService {
-	return &orderQueryService{
+func NewOrderQuery(client mysql.ClientContext) service.OrderQuery {
+	return &orderQuery{
 		client: client,
 	}
 }
 
-type orderQueryService struct {
+type orderQuery struct {
 	client mysql.ClientContext
 }
 
-func (s *orderQueryService) GetOrder(ctx context.Context, orderID uuid.UUID) (*Order, error) {
+func (q *orderQuery) GetOrder(ctx context.Context, orderID uuid.UUID) (*appmodel.OrderDetails, error) {
 	orderData := struct {
 		OrderID    uuid.UUID `db:"order_id"`
 		UserID     uuid.UUID `db:"user_id"`
@@ -50,10 +33,10 @@ func (s *orderQueryService) GetOrder(ctx context.Context, orderID uuid.UUID) (*O
 		UpdatedAt  time.Time `db:"updated_at"`
 	}{}
 
-	err := s.client.GetContext(
+	err := q.client.GetContext(
 		ctx,
 		&orderData,
-		`SELECT order_id, user_id, status, total_price, created_at, updated_at FROM orders WHERE order_id = ?`,
+		`SELECT order_id, user_id, status, total_price, created_at, updated_at FROM orders WHERE order_id = UUID_TO_BIN(?)`,
 		orderID,
 	)
 	if err != nil {
@@ -69,26 +52,26 @@ func (s *orderQueryService) GetOrder(ctx context.Context, orderID uuid.UUID) (*O
 		Price     float64   `db:"price"`
 	}
 
-	err = s.client.SelectContext(
+	err = q.client.SelectContext(
 		ctx,
 		&itemsData,
-		`SELECT product_id, quantity, price FROM order_items WHERE order_id = ?`,
+		`SELECT product_id, quantity, price FROM order_items WHERE order_id = UUID_TO_BIN(?)`,
 		orderID,
 	)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	items := make([]OrderItem, 0, len(itemsData))
+	items := make([]appmodel.OrderDetailsItem, 0, len(itemsData))
 	for _, item := range itemsData {
-		items = append(items, OrderItem{
+		items = append(items, appmodel.OrderDetailsItem{
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
 			Price:     item.Price,
 		})
 	}
 
-	return &Order{
+	return &appmodel.OrderDetails{
 		OrderID:    orderData.OrderID,
 		UserID:     orderData.UserID,
 		Status:     orderData.Status,
@@ -98,3 +81,37 @@ func (s *orderQueryService) GetOrder(ctx context.Context, orderID uuid.UUID) (*O
 		UpdatedAt:  orderData.UpdatedAt,
 	}, nil
 }
+
+func (q *orderQuery) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]appmodel.OrderHistoryEvent, error) {
+	var eventsData []struct {
+		EventID    int64     `db:"event_id"`
+		EventType  string    `db:"event_type"`
+		Payload    string    `db:"payload"`
+		Actor      string    `db:"actor"`
+		Source     string    `db:"source"`
+		OccurredAt time.Time `db:"occurred_at"`
+	}
+
+	err := q.client.SelectContext(
+		ctx,
+		&eventsData,
+		`SELECT event_id, event_type, payload, actor, source, occurred_at FROM order_events WHERE order_id = UUID_TO_BIN(?) ORDER BY event_id`,
+		orderID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	events := make([]appmodel.OrderHistoryEvent, 0, len(eventsData))
+	for _, event := range eventsData {
+		events = append(events, appmodel.OrderHistoryEvent{
+			EventID:    event.EventID,
+			Type:       event.EventType,
+			Payload:    event.Payload,
+			Actor:      event.Actor,
+			Source:     event.Source,
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,66 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+// NewOrderCountQuery counts orders using idx_user_id_created_at index
+func NewOrderCountQuery(client mysql.ClientContext) service.OrderCountQuery {
+	return &orderCountQuery{
+		client: client,
+	}
+}
+
+type orderCountQuery struct {
+	client mysql.ClientContext
+}
+
+// LockCustomerOrders locks row of customer in customer_order_locks, the row is created on first order of customer
+func (q *orderCountQuery) LockCustomerOrders(ctx context.Context, customerID uuid.UUID) error {
+	_, err := q.client.ExecContext(
+		ctx,
+		`INSERT INTO customer_order_locks (user_id) VALUES (UUID_TO_BIN(?)) ON DUPLICATE KEY UPDATE user_id = user_id`,
+		customerID,
+	)
+	return errors.WithStack(err)
+}
+
+func (q *orderCountQuery) CountOpenOrders(ctx context.Context, customerID uuid.UUID) (int, error) {
+	var count int
+	err := q.client.GetContext(
+		ctx,
+		&count,
+		`SELECT COUNT(*) FROM orders WHERE user_id = UUID_TO_BIN(?) AND status IN (?, ?)`,
+		customerID,
+		model.Open,
+		model.Pending,
+	)
+	return count, errors.WithStack(err)
+}
+
+func (q *orderCountQuery) CountOrdersSince(ctx context.Context, customerID uuid.UUID, since time.Time) (int, time.Time, error) {
+	result := struct {
+		Count  int          `db:"count"`
+		Oldest sql.NullTime `db:"oldest"`
+	}{}
+	err := q.client.GetContext(
+		ctx,
+		&result,
+		`SELECT COUNT(*) AS count, MIN(created_at) AS oldest FROM orders WHERE user_id = UUID_TO_BIN(?) AND created_at >= ?`,
+		customerID,
+		since,
+	)
+	if err != nil {
+		return 0, time.Time{}, errors.WithStack(err)
+	}
+	return result.Count, result.Oldest.Time, nil
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"slices"
+	"strings"
 	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
@@ -12,34 +14,36 @@ import (
 	"order/pkg/domain/model"
 )
 
-func NewOrderRepository(ctx context.Context, client mysql.ClientContext) model.OrderRepository {
+// itemsBatchSize limits rows per multi-row statement to keep placeholders count far below MySQL limit
+const itemsBatchSize = 500
+
+func NewOrderRepository(client mysql.ClientContext) model.OrderRepository {
 	return &orderRepository{
-		ctx:    ctx,
 		client: client,
 	}
 }
 
 type orderRepository struct {
-	ctx    context.Context
 	client mysql.ClientContext
 }
 
-func (r *orderRepository) NextID() (uuid.UUID, error) {
+func (r *orderRepository) NextID(_ context.Context) (uuid.UUID, error) {
 	return uuid.NewV7()
 }
 
-func (r *orderRepository) Store(order *model.Order) error {
-	_, err := r.client.ExecContext(r.ctx,
+func (r *orderRepository) Store(ctx context.Context, order *model.Order) error {
+	_, err := r.client.ExecContext(ctx,
 		`
-INSERT INTO orders (order_id, user_id, status, total_price, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO orders (order_id, user_id, status, total_price, created_at, updated_at) VALUES (UUID_TO_BIN(?), UUID_TO_BIN(?), ?, ?, ?, ?)
 ON DUPLICATE KEY UPDATE
 status = VALUES(status),
+total_price = VALUES(total_price),
 updated_at = VALUES(updated_at)
 `,
 		order.ID,
 		order.CustomerID,
 		order.Status,
-		0.0, // Total price logic to be refined
+		order.TotalPrice(),
 		order.CreatedAt,
 		order.UpdatedAt,
 	)
@@ -47,30 +51,82 @@ updated_at = VALUES(updated_at)
 		return errors.WithStack(err)
 	}
 
-	// Simple implementation: delete all items and re-insert (not efficient but simple for now)
-	_, err = r.client.ExecContext(r.ctx, `DELETE FROM order_items WHERE order_id = ?`, order.ID)
+	changes, tracked := order.ItemChanges()
+	if !tracked {
+		_, err = r.client.ExecContext(ctx, `DELETE FROM order_items WHERE order_id = UUID_TO_BIN(?)`, order.ID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		changes = model.ItemChanges{Upserted: order.Items}
+	}
+
+	err = r.deleteItems(ctx, order.ID, changes.Removed)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
+	err = r.upsertItems(ctx, order.ID, changes.Upserted)
+	if err != nil {
+		return err
+	}
+
+	order.MarkItemsPersisted()
+	return nil
+}
 
-	for _, item := range order.Items {
-		_, err = r.client.ExecContext(r.ctx,
-			`INSERT INTO order_items (item_id, order_id, product_id, quantity, price) VALUES (?, ?, ?, ?, ?)`,
-			item.ID,
-			order.ID,
-			item.ProductID,
-			1, // Quantity default 1 for now
-			item.Price,
+func (r *orderRepository) upsertItems(ctx context.Context, orderID uuid.UUID, items []model.Item) error {
+	for batch := range slices.Chunk(items, itemsBatchSize) {
+		if err := ctx.Err(); err != nil {
+			return errors.WithStack(err)
+		}
+		args := make([]interface{}, 0, len(batch)*5)
+		for _, item := range batch {
+			args = append(args,
+				item.ID,
+				orderID,
+				item.ProductID,
+				1, // Quantity default 1 for now
+				item.Price,
+			)
+		}
+		_, err := r.client.ExecContext(ctx,
+			`INSERT INTO order_items (item_id, order_id, product_id, quantity, price) VALUES `+
+				placeholders(len(batch), "(UUID_TO_BIN(?), UUID_TO_BIN(?), UUID_TO_BIN(?), ?, ?)")+`
+ON DUPLICATE KEY UPDATE
+product_id = VALUES(product_id),
+quantity = VALUES(quantity),
+price = VALUES(price)
+`,
+			args...,
 		)
 		if err != nil {
 			return errors.WithStack(err)
 		}
 	}
+	return nil
+}
 
+func (r *orderRepository) deleteItems(ctx context.Context, orderID uuid.UUID, itemIDs []uuid.UUID) error {
+	for batch := range slices.Chunk(itemIDs, itemsBatchSize) {
+		if err := ctx.Err(); err != nil {
+			return errors.WithStack(err)
+		}
+		args := make([]interface{}, 0, len(batch)+1)
+		args = append(args, orderID)
+		for _, itemID := range batch {
+			args = append(args, itemID)
+		}
+		_, err := r.client.ExecContext(ctx,
+			`DELETE FROM order_items WHERE order_id = UUID_TO_BIN(?) AND item_id IN (`+placeholders(len(batch), "UUID_TO_BIN(?)")+`)`,
+			args...,
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
 	return nil
 }
 
-func (r *orderRepository) Find(id uuid.UUID) (*model.Order, error) {
+func (r *orderRepository) Find(ctx context.Context, id uuid.UUID) (*model.Order, error) {
 	orderData := struct {
 		OrderID    uuid.UUID `db:"order_id"`
 		UserID     uuid.UUID `db:"user_id"`
@@ -81,9 +137,9 @@ func (r *orderRepository) Find(id uuid.UUID) (*model.Order, error) {
 	}{}
 
 	err := r.client.GetContext(
-		r.ctx,
+		ctx,
 		&orderData,
-		`SELECT order_id, user_id, status, total_price, created_at, updated_at FROM orders WHERE order_id = ?`,
+		`SELECT order_id, user_id, status, total_price, created_at, updated_at FROM orders WHERE order_id = UUID_TO_BIN(?)`,
 		id,
 	)
 	if err != nil {
@@ -100,9 +156,9 @@ func (r *orderRepository) Find(id uuid.UUID) (*model.Order, error) {
 	}
 
 	err = r.client.SelectContext(
-		r.ctx,
+		ctx,
 		&itemsData,
-		`SELECT item_id, product_id, price FROM order_items WHERE order_id = ?`,
+		`SELECT item_id, product_id, price FROM order_items WHERE order_id = UUID_TO_BIN(?)`,
 		id,
 	)
 	if err != nil {
@@ -118,18 +174,25 @@ func (r *orderRepository) Find(id uuid.UUID) (*model.Order, error) {
 		})
 	}
 
-	return &model.Order{
+	order := &model.Order{
 		ID:         orderData.OrderID,
 		CustomerID: orderData.UserID,
 		Status:     model.OrderStatus(orderData.Status),
 		Items:      items,
 		CreatedAt:  orderData.CreatedAt,
 		UpdatedAt:  orderData.UpdatedAt,
-	}, nil
+	}
+	order.MarkItemsPersisted()
+	return order, nil
 }
 
-func (r *orderRepository) Delete(id uuid.UUID) error {
+func (r *orderRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Soft delete not implemented in DB schema yet, so hard delete
-	_, err := r.client.ExecContext(r.ctx, `DELETE FROM orders WHERE order_id = ?`, id)
+	_, err := r.client.ExecContext(ctx, `DELETE FROM orders WHERE order_id = UUID_TO_BIN(?)`, id)
 	return errors.WithStack(err)
 }
+
+// placeholders builds comma separated list of n repeated placeholder groups for multi-row statements
+func placeholders(n int, group string) string {
+	return strings.TrimSuffix(strings.Repeat(group+", ", n), ", ")
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"order/pkg/domain/model"
+)
+
+func NewOrderEventRepository(client mysql.ClientContext) model.OrderEventRepository {
+	return &orderEventRepository{
+		client: client,
+	}
+}
+
+type orderEventRepository struct {
+	client mysql.ClientContext
+}
+
+func (r *orderEventRepository) Append(ctx context.Context, record *model.OrderEventRecord) error {
+	payload, err := json.Marshal(record.Event)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	result, err := r.client.ExecContext(ctx,
+		`INSERT INTO order_events (order_id, event_type, payload, actor, source, occurred_at) VALUES (UUID_TO_BIN(?), ?, ?, ?, ?, ?)`,
+		record.OrderID,
+		record.Event.Type(),
+		payload,
+		record.Actor,
+		record.Source,
+		record.OccurredAt,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	record.ID, err = result.LastInsertId()
+	return errors.WithStack(err)
+}
+
+func (r *orderEventRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]model.OrderEventRecord, error) {
+	var eventsData []struct {
+		EventID    int64     `db:"event_id"`
+		OrderID    uuid.UUID `db:"order_id"`
+		EventType  string    `db:"event_type"`
+		Payload    []byte    `db:"payload"`
+		Actor      string    `db:"actor"`
+		Source     string    `db:"source"`
+		OccurredAt time.Time `db:"occurred_at"`
+	}
+
+	err := r.client.SelectContext(
+		ctx,
+		&eventsData,
+		`SELECT event_id, order_id, event_type, payload, actor, source, occurred_at FROM order_events WHERE order_id = UUID_TO_BIN(?) ORDER BY event_id`,
+		orderID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	records := make([]model.OrderEventRecord, 0, len(eventsData))
+	for _, eventData := range eventsData {
+		event, err := decodeOrderEvent(eventData.EventType, eventData.Payload)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, model.OrderEventRecord{
+			ID:         eventData.EventID,
+			OrderID:    eventData.OrderID,
+			Event:      event,
+			Actor:      eventData.Actor,
+			Source:     eventData.Source,
+			OccurredAt: eventData.OccurredAt,
+		})
+	}
+	return records, nil
+}
+
+func decodeOrderEvent(eventType string, payload []byte) (model.OrderEvent, error) {
+	switch eventType {
+	case model.OrderCreated{}.Type():
+		return decodePayload[model.OrderCreated](payload)
+	case model.OrderItemChanged{}.Type():
+		return decodePayload[model.OrderItemChanged](payload)
+	case model.OrderStatusChanged{}.Type():
+		return decodePayload[model.OrderStatusChanged](payload)
+	case model.OrderDeleted{}.Type():
+		return decodePayload[model.OrderDeleted](payload)
+	default:
+		return nil, errors.Errorf("unknown order event type %q", eventType)
+	}
+}
+
+func decodePayload[T model.OrderEvent](payload []byte) (model.OrderEvent, error) {
+	var event T
+	err := json.Unmarshal(payload, &event)
+	return event, errors.WithStack(err)
+}
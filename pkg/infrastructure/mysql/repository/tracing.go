@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"order/pkg/domain/model"
+)
+
+var tracer = otel.Tracer("order/pkg/infrastructure/mysql/repository")
+
+// NewTracedOrderRepository wraps every repository call into span, statements of single call share its span
+func NewTracedOrderRepository(repo model.OrderRepository) model.OrderRepository {
+	return &tracedOrderRepository{
+		repo: repo,
+	}
+}
+
+type tracedOrderRepository struct {
+	repo model.OrderRepository
+}
+
+func (r *tracedOrderRepository) NextID(ctx context.Context) (uuid.UUID, error) {
+	return r.repo.NextID(ctx)
+}
+
+func (r *tracedOrderRepository) Store(ctx context.Context, order *model.Order) (err error) {
+	ctx, span := startSpan(ctx, "OrderRepository.Store")
+	defer func() { endSpan(span, err) }()
+	return r.repo.Store(ctx, order)
+}
+
+func (r *tracedOrderRepository) Find(ctx context.Context, id uuid.UUID) (_ *model.Order, err error) {
+	ctx, span := startSpan(ctx, "OrderRepository.Find")
+	defer func() { endSpan(span, err) }()
+	return r.repo.Find(ctx, id)
+}
+
+func (r *tracedOrderRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "OrderRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.repo.Delete(ctx, id)
+}
+
+func NewTracedOrderEventRepository(repo model.OrderEventRepository) model.OrderEventRepository {
+	return &tracedOrderEventRepository{
+		repo: repo,
+	}
+}
+
+type tracedOrderEventRepository struct {
+	repo model.OrderEventRepository
+}
+
+func (r *tracedOrderEventRepository) Append(ctx context.Context, record *model.OrderEventRecord) (err error) {
+	ctx, span := startSpan(ctx, "OrderEventRepository.Append")
+	defer func() { endSpan(span, err) }()
+	return r.repo.Append(ctx, record)
+}
+
+func (r *tracedOrderEventRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) (_ []model.OrderEventRecord, err error) {
+	ctx, span := startSpan(ctx, "OrderEventRepository.FindByOrderID")
+	defer func() { endSpan(span, err) }()
+	return r.repo.FindByOrderID(ctx, orderID)
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemMySQL),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
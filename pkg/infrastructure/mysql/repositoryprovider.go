@@ -1,12 +1,11 @@
 package mysql
 
 import (
-	"context"
-
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 
 	"order/pkg/application/service"
 	"order/pkg/domain/model"
+	"order/pkg/infrastructure/mysql/query"
 	"order/pkg/infrastructure/mysql/repository"
 )
 
@@ -18,6 +17,18 @@ type repositoryProvider struct {
 	client mysql.ClientContext
 }
 
-func (r *repositoryProvider) OrderRepository(ctx context.Context) model.OrderRepository {
-	return repository.NewOrderRepository(ctx, r.client)
+func (r *repositoryProvider) OrderRepository() model.OrderRepository {
+	return repository.NewTracedOrderRepository(repository.NewOrderRepository(r.client))
+}
+
+func (r *repositoryProvider) OrderEventRepository() model.OrderEventRepository {
+	return repository.NewTracedOrderEventRepository(repository.NewOrderEventRepository(r.client))
+}
+
+func (r *repositoryProvider) OrderCountQuery() service.OrderCountQuery {
+	return query.NewOrderCountQuery(r.client)
+}
+
+func (r *repositoryProvider) OrderQuery() service.OrderQuery {
+	return query.NewOrderQuery(r.client)
 }
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type statement struct {
+	query string
+	args  []interface{}
+}
+
+// mockClient records executed statements instead of sending them to database, reads are not supported
+type mockClient struct {
+	// roundTrip simulates database latency, statements are not recorded when it is set
+	roundTrip  time.Duration
+	onExec     func(count int)
+	count      int
+	statements []statement
+}
+
+func (m *mockClient) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (m *mockClient) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (m *mockClient) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	m.count++
+	if m.roundTrip > 0 {
+		// busy wait because time.Sleep resolution is too coarse for microseconds
+		for start := time.Now(); time.Since(start) < m.roundTrip; {
+		}
+		return driver.RowsAffected(1), nil
+	}
+	m.statements = append(m.statements, statement{query: strings.TrimSpace(query), args: args})
+	if m.onExec != nil {
+		m.onExec(m.count)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (m *mockClient) SelectContext(context.Context, interface{}, string, ...interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (m *mockClient) GetContext(context.Context, interface{}, string, ...interface{}) error {
+	return fmt.Errorf("not supported")
+}
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+	"order/pkg/infrastructure/mysql/repository"
+)
+
+func TestOrderRepositoryStore(t *testing.T) {
+	t.Run("New order items are inserted with single statement", func(t *testing.T) {
+		client := &mockClient{}
+		repo := repository.NewOrderRepository(client)
+
+		order := newOrder(3)
+		require.NoError(t, repo.Store(context.Background(), order))
+
+		require.Len(t, client.statements, 3)
+		require.Contains(t, client.statements[1].query, "DELETE FROM order_items WHERE order_id = UUID_TO_BIN(?)")
+		require.Contains(t, client.statements[2].query, "INSERT INTO order_items")
+		require.Len(t, client.statements[2].args, 3*5)
+	})
+
+	t.Run("Total price of items is stored with order", func(t *testing.T) {
+		client := &mockClient{}
+		repo := repository.NewOrderRepository(client)
+
+		order := newOrder(3)
+		require.NoError(t, repo.Store(context.Background(), order))
+
+		require.Contains(t, client.statements[0].query, "total_price = VALUES(total_price)")
+		require.InDelta(t, 3*9.99, client.statements[0].args[3], 1e-9)
+	})
+
+	t.Run("Large orders are inserted in batches", func(t *testing.T) {
+		client := &mockClient{}
+		repo := repository.NewOrderRepository(client)
+
+		require.NoError(t, repo.Store(context.Background(), newOrder(1200)))
+
+		// orders upsert, items cleanup and 3 batches of items
+		require.Len(t, client.statements, 5)
+	})
+
+	t.Run("Only added item is stored for tracked order", func(t *testing.T) {
+		client := &mockClient{}
+		repo := repository.NewOrderRepository(client)
+
+		order := newOrder(100)
+		order.MarkItemsPersisted()
+		order.Items = append(order.Items, newItem())
+		require.NoError(t, repo.Store(context.Background(), order))
+
+		require.Len(t, client.statements, 2)
+		require.Contains(t, client.statements[1].query, "INSERT INTO order_items")
+		require.Len(t, client.statements[1].args, 5)
+	})
+
+	t.Run("Removed and changed items are synced for tracked order", func(t *testing.T) {
+		client := &mockClient{}
+		repo := repository.NewOrderRepository(client)
+
+		order := newOrder(3)
+		order.MarkItemsPersisted()
+		removedItemID := order.Items[0].ID
+		order.Items = order.Items[1:]
+		order.Items[0].Price = 42
+		require.NoError(t, repo.Store(context.Background(), order))
+
+		require.Len(t, client.statements, 3)
+		require.Contains(t, client.statements[1].query, "DELETE FROM order_items WHERE order_id = UUID_TO_BIN(?) AND item_id IN (UUID_TO_BIN(?))")
+		require.Equal(t, []interface{}{order.ID, removedItemID}, client.statements[1].args)
+		require.Contains(t, client.statements[2].query, "ON DUPLICATE KEY UPDATE")
+		require.Len(t, client.statements[2].args, 5)
+	})
+
+	t.Run("Cancellation stops storing remaining batches", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		client := &mockClient{onExec: func(count int) {
+			if count == 3 {
+				cancel()
+			}
+		}}
+		repo := repository.NewOrderRepository(client)
+
+		err := repo.Store(ctx, newOrder(1200))
+		require.ErrorIs(t, err, context.Canceled)
+		require.Len(t, client.statements, 3)
+	})
+
+	t.Run("Unchanged items are not stored again", func(t *testing.T) {
+		client := &mockClient{}
+		repo := repository.NewOrderRepository(client)
+
+		order := newOrder(3)
+		require.NoError(t, repo.Store(context.Background(), order))
+		client.statements = nil
+
+		order.Status = model.Pending
+		require.NoError(t, repo.Store(context.Background(), order))
+		require.Len(t, client.statements, 1)
+	})
+}
+
+// BenchmarkOrderRepositoryStore compares adding single item to large order with previous
+// delete-and-reinsert strategy, every statement simulates network round trip to database
+func BenchmarkOrderRepositoryStore(b *testing.B) {
+	for _, itemsCount := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("delete and reinsert/%d items", itemsCount), func(b *testing.B) {
+			client := &mockClient{roundTrip: simulatedRoundTrip}
+			order := newOrder(itemsCount)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				order.Items = append(order.Items[:itemsCount], newItem())
+				b.StartTimer()
+				require.NoError(b, storeByDeleteAndReinsert(client, order))
+			}
+			b.ReportMetric(float64(client.count)/float64(b.N), "statements/op")
+		})
+
+		b.Run(fmt.Sprintf("change tracking/%d items", itemsCount), func(b *testing.B) {
+			client := &mockClient{roundTrip: simulatedRoundTrip}
+			repo := repository.NewOrderRepository(client)
+			order := newOrder(itemsCount)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				order.Items = order.Items[:itemsCount]
+				order.MarkItemsPersisted()
+				order.Items = append(order.Items, newItem())
+				b.StartTimer()
+				require.NoError(b, repo.Store(context.Background(), order))
+			}
+			b.ReportMetric(float64(client.count)/float64(b.N), "statements/op")
+		})
+	}
+}
+
+const simulatedRoundTrip = 50 * time.Microsecond
+
+// storeByDeleteAndReinsert reproduces previous implementation of order repository Store
+func storeByDeleteAndReinsert(client *mockClient, order *model.Order) error {
+	ctx := context.Background()
+	_, err := client.ExecContext(ctx, `INSERT INTO orders (order_id, user_id, status, total_price, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		order.ID, order.CustomerID, order.Status, 0.0, order.CreatedAt, order.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = client.ExecContext(ctx, `DELETE FROM order_items WHERE order_id = ?`, order.ID)
+	if err != nil {
+		return err
+	}
+	for _, item := range order.Items {
+		_, err = client.ExecContext(ctx, `INSERT INTO order_items (item_id, order_id, product_id, quantity, price) VALUES (?, ?, ?, ?, ?)`,
+			item.ID, order.ID, item.ProductID, 1, item.Price)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newOrder(itemsCount int) *model.Order {
+	currentTime := time.Now()
+	order := &model.Order{
+		ID:         uuid.Must(uuid.NewV7()),
+		CustomerID: uuid.Must(uuid.NewV7()),
+		Status:     model.Open,
+		CreatedAt:  currentTime,
+		UpdatedAt:  currentTime,
+	}
+	for i := 0; i < itemsCount; i++ {
+		order.Items = append(order.Items, newItem())
+	}
+	return order
+}
+
+func newItem() model.Item {
+	return model.Item{
+		ID:        uuid.Must(uuid.NewV7()),
+		ProductID: uuid.Must(uuid.NewV7()),
+		Price:     9.99,
+	}
+}
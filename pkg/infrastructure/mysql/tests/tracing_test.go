@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"order/pkg/infrastructure/mysql/repository"
+)
+
+func TestTracedOrderRepository(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	repo := repository.NewTracedOrderRepository(repository.NewOrderRepository(&mockClient{}))
+
+	require.NoError(t, repo.Store(context.Background(), newOrder(3)))
+	_, err := repo.Find(context.Background(), uuid.New())
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "OrderRepository.Store", spans[0].Name())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, "OrderRepository.Find", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -2,25 +2,99 @@ package mysql
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 
 	"order/pkg/application/service"
 )
 
-func NewUnitOfWork(uow mysql.UnitOfWorkWithRepositoryProvider[service.RepositoryProvider]) service.UnitOfWork {
+func NewUnitOfWork(
+	uow mysql.UnitOfWorkWithRepositoryProvider[service.RepositoryProvider],
+	logger logging.Logger,
+) service.UnitOfWork {
 	return &unitOfWork{
-		uow: uow,
+		uow:    uow,
+		logger: logger,
+		hooks:  make(map[context.Context]*postCommitHooks),
 	}
 }
 
 type unitOfWork struct {
-	uow mysql.UnitOfWorkWithRepositoryProvider[service.RepositoryProvider]
+	uow    mysql.UnitOfWorkWithRepositoryProvider[service.RepositoryProvider]
+	logger logging.Logger
+
+	mu    sync.Mutex
+	hooks map[context.Context]*postCommitHooks
+}
+
+// postCommitHooks mirrors shared transaction of golib unit of work: nested executions with the same context
+// share one transaction, so hooks are run only when the outermost execution has committed it
+type postCommitHooks struct {
+	count  int
+	failed bool
+	hooks  []service.PostCommitHook
 }
 
 func (u *unitOfWork) Execute(ctx context.Context, f func(provider service.RepositoryProvider) error) error {
-	return u.uow.ExecuteWithRepositoryProvider(ctx, f)
+	u.acquireHooks(ctx)
+	err := u.uow.ExecuteWithRepositoryProvider(ctx, f)
+	hooks := u.releaseHooks(ctx, err != nil)
+	if err != nil {
+		return err
+	}
+
+	// transaction is already committed, so failed hook must not make caller retry and duplicate written data
+	for _, hook := range hooks {
+		if hookErr := hook(ctx); hookErr != nil {
+			u.logger.Error(hookErr, "post-commit hook failed")
+		}
+	}
+	return nil
+}
+
+func (u *unitOfWork) AfterCommit(ctx context.Context, hook service.PostCommitHook) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	h, ok := u.hooks[ctx]
+	if !ok {
+		return service.ErrNoActiveUnitOfWork
+	}
+	h.hooks = append(h.hooks, hook)
+	return nil
+}
+
+func (u *unitOfWork) acquireHooks(ctx context.Context) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	h, ok := u.hooks[ctx]
+	if !ok {
+		h = &postCommitHooks{}
+		u.hooks[ctx] = h
+	}
+	h.count++
+}
+
+// releaseHooks returns hooks ready to run, nil until the outermost execution is finished successfully
+func (u *unitOfWork) releaseHooks(ctx context.Context, failed bool) []service.PostCommitHook {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	h := u.hooks[ctx]
+	h.failed = h.failed || failed
+	h.count--
+	if h.count > 0 {
+		return nil
+	}
+	delete(u.hooks, ctx)
+	if h.failed {
+		return nil
+	}
+	return h.hooks
 }
 
 func NewLockableUnitOfWork(uow mysql.LockableUnitOfWorkWithRepositoryProvider[service.RepositoryProvider]) service.LockableUnitOfWork {
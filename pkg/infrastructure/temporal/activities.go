@@ -2,12 +2,16 @@ package temporal
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	appmodel "order/pkg/application/model"
 	"order/pkg/application/service"
+	"order/pkg/domain/model"
 	domainservice "order/pkg/domain/service"
 
 	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
 )
 
 type Activities struct {
@@ -15,6 +19,8 @@ type Activities struct {
 	ProductService      service.ProductService
 	PaymentService      service.PaymentService
 	NotificationService service.NotificationService
+	ExpiredOrderQuery   service.ExpiredOrderQuery
+	QuotaChecker        service.QuotaChecker
 }
 
 func NewActivities(
@@ -22,47 +28,145 @@ func NewActivities(
 	productService service.ProductService,
 	paymentService service.PaymentService,
 	notificationService service.NotificationService,
+	expiredOrderQuery service.ExpiredOrderQuery,
+	quotaChecker service.QuotaChecker,
 ) *Activities {
 	return &Activities{
 		UoW:                 uow,
 		ProductService:      productService,
 		PaymentService:      paymentService,
 		NotificationService: notificationService,
+		ExpiredOrderQuery:   expiredOrderQuery,
+		QuotaChecker:        quotaChecker,
 	}
 }
 
+// CreateOrderActivity checks quota in the same transaction which creates order,
+// so concurrent workflows of one customer can't exceed it
 func (a *Activities) CreateOrderActivity(ctx context.Context, order appmodel.Order) (uuid.UUID, error) {
+	ctx = withActivityInitiator(ctx)
 	var orderID uuid.UUID
 	err := a.UoW.Execute(ctx, func(provider service.RepositoryProvider) error {
-		domainService := domainservice.NewOrderService(provider.OrderRepository(ctx), &service.NoOpEventDispatcher{})
-		var err error
-		orderID, err = domainService.CreateOrder(order.UserID)
+		err := a.QuotaChecker.CheckCreateOrder(ctx, provider.OrderCountQuery(), order.UserID)
+		if err != nil {
+			return err
+		}
+
+		domainService := domainservice.NewOrderService(
+			provider.OrderRepository(),
+			service.NewOrderHistoryDispatcher(provider.OrderEventRepository()),
+		)
+		orderID, err = domainService.CreateOrder(ctx, order.UserID)
 		return err
 	})
-	return orderID, err
+	return orderID, classifyError(err)
 }
 
 func (a *Activities) GetProductPriceActivity(ctx context.Context, productID uuid.UUID) (float64, error) {
-	return a.ProductService.GetPrice(ctx, productID)
+	price, err := a.ProductService.GetPrice(ctx, productID)
+	return price, classifyError(err)
 }
 
 func (a *Activities) AddItemActivity(ctx context.Context, orderID uuid.UUID, productID uuid.UUID, price float64, quantity int) error {
-	return a.UoW.Execute(ctx, func(provider service.RepositoryProvider) error {
-		domainService := domainservice.NewOrderService(provider.OrderRepository(ctx), &service.NoOpEventDispatcher{})
+	ctx = withActivityInitiator(ctx)
+	err := a.UoW.Execute(ctx, func(provider service.RepositoryProvider) error {
+		domainService := domainservice.NewOrderService(
+			provider.OrderRepository(),
+			service.NewOrderHistoryDispatcher(provider.OrderEventRepository()),
+		)
 		for i := 0; i < quantity; i++ {
-			_, err := domainService.AddItem(orderID, productID, price)
+			_, err := domainService.AddItem(ctx, orderID, productID, price)
 			if err != nil {
 				return err
 			}
 		}
 		return nil
 	})
+	return classifyError(err)
 }
 
 func (a *Activities) ProcessPaymentActivity(ctx context.Context, userID, orderID uuid.UUID, amount float64) error {
-	return a.PaymentService.ProcessPayment(ctx, userID, orderID, amount)
+	return classifyError(a.PaymentService.ProcessPayment(ctx, userID, orderID, amount))
+}
+
+// MarkOrderPaidActivity moves order to paid once payment succeeded, so order expiry doesn't cancel it.
+// Order cancelled while it was paid for is not retried, it needs refund
+func (a *Activities) MarkOrderPaidActivity(ctx context.Context, orderID uuid.UUID) error {
+	ctx = withActivityInitiator(ctx)
+	err := a.UoW.Execute(ctx, func(provider service.RepositoryProvider) error {
+		order, err := provider.OrderRepository().Find(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status == model.Cancelled {
+			return domainservice.ErrInvalidOrderStatus
+		}
+
+		domainService := domainservice.NewOrderService(
+			provider.OrderRepository(),
+			service.NewOrderHistoryDispatcher(provider.OrderEventRepository()),
+		)
+		return domainService.SetStatus(ctx, orderID, model.Paid)
+	})
+	return classifyError(err)
 }
 
 func (a *Activities) SendNotificationActivity(ctx context.Context, userID uuid.UUID, message string) error {
-	return a.NotificationService.SendNotification(ctx, userID, message)
+	return classifyError(a.NotificationService.SendNotification(ctx, userID, message))
+}
+
+func (a *Activities) FindExpiredOrdersActivity(ctx context.Context, createdBefore time.Time, limit int) ([]uuid.UUID, error) {
+	return a.ExpiredOrderQuery.FindExpiredOrders(ctx, createdBefore, limit)
+}
+
+// ExpireOrderResult tells whether order is cancelled and whom to notify about it
+type ExpireOrderResult struct {
+	Expired    bool
+	CustomerID uuid.UUID
+}
+
+// ExpireOrderActivity cancels order which is still open or pending and created before createdBefore,
+// order paid, cancelled or deleted since it was found is left as is
+func (a *Activities) ExpireOrderActivity(ctx context.Context, orderID uuid.UUID, createdBefore time.Time) (ExpireOrderResult, error) {
+	ctx = withActivityInitiator(ctx)
+	var result ExpireOrderResult
+	err := a.UoW.Execute(ctx, func(provider service.RepositoryProvider) error {
+		order, err := provider.OrderRepository().Find(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != model.Open && order.Status != model.Pending || !order.CreatedAt.Before(createdBefore) {
+			return nil
+		}
+
+		domainService := domainservice.NewOrderService(
+			provider.OrderRepository(),
+			service.NewOrderHistoryDispatcher(provider.OrderEventRepository()),
+		)
+		err = domainService.SetStatus(ctx, orderID, model.Cancelled)
+		if err != nil {
+			return err
+		}
+		result = ExpireOrderResult{
+			Expired:    true,
+			CustomerID: order.CustomerID,
+		}
+		return nil
+	})
+	if errors.Is(err, model.ErrOrderNotFound) {
+		return ExpireOrderResult{}, nil
+	}
+	return result, classifyError(err)
+}
+
+// withActivityInitiator marks order changes made by activity with its workflow and activity type
+func withActivityInitiator(ctx context.Context) context.Context {
+	if !activity.IsActivity(ctx) {
+		return ctx
+	}
+	info := activity.GetInfo(ctx)
+	return service.WithInitiator(ctx, service.Initiator{
+		Actor:  "workflow:" + info.WorkflowExecution.ID,
+		Source: "temporal:" + info.ActivityType.Name,
+	})
 }
@@ -0,0 +1,72 @@
+package temporal
+
+import (
+	"errors"
+
+	"go.temporal.io/sdk/temporal"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+)
+
+// Error types are stable names of business errors, workflows and clients match failures by them
+const (
+	OrderNotFoundErrorType      = "OrderNotFound"
+	InvalidOrderStatusErrorType = "InvalidOrderStatus"
+	ProductNotFoundErrorType    = "ProductNotFound"
+	PaymentDeclinedErrorType    = "PaymentDeclined"
+	// InvalidPaymentRequestErrorType is not business error, but repeating the same request can't fix it
+	InvalidPaymentRequestErrorType = "InvalidPaymentRequest"
+	QuotaExceededErrorType         = "QuotaExceeded"
+)
+
+var nonRetryableErrors = map[error]string{
+	model.ErrOrderNotFound:              OrderNotFoundErrorType,
+	domainservice.ErrInvalidOrderStatus: InvalidOrderStatusErrorType,
+	service.ErrProductNotFound:          ProductNotFoundErrorType,
+	service.ErrPaymentDeclined:          PaymentDeclinedErrorType,
+	service.ErrInvalidPaymentRequest:    InvalidPaymentRequestErrorType,
+	service.ErrQuotaExceeded:            QuotaExceededErrorType,
+}
+
+// nonRetryableErrorTypes are listed in retry policy too, so errors are not retried even if activity wraps them differently
+var nonRetryableErrorTypes = []string{
+	OrderNotFoundErrorType,
+	InvalidOrderStatusErrorType,
+	ProductNotFoundErrorType,
+	PaymentDeclinedErrorType,
+	InvalidPaymentRequestErrorType,
+	QuotaExceededErrorType,
+}
+
+// classifyError turns business errors into non-retryable application errors, other errors are retried
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var quotaErr *service.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		// quota and retry delay are passed as details to restore error for caller of workflow
+		return temporal.NewNonRetryableApplicationError(err.Error(), QuotaExceededErrorType, err, quotaErr)
+	}
+	for target, errorType := range nonRetryableErrors {
+		if errors.Is(err, target) {
+			return temporal.NewNonRetryableApplicationError(err.Error(), errorType, err)
+		}
+	}
+	return err
+}
+
+// quotaExceededError restores quota error of workflow activity, other errors are returned as is
+func quotaExceededError(err error) error {
+	var applicationErr *temporal.ApplicationError
+	if !errors.As(err, &applicationErr) || applicationErr.Type() != QuotaExceededErrorType {
+		return err
+	}
+	var quotaErr service.QuotaExceededError
+	if applicationErr.HasDetails() && applicationErr.Details(&quotaErr) == nil {
+		return &quotaErr
+	}
+	return &service.QuotaExceededError{Quota: applicationErr.Message()}
+}
@@ -0,0 +1,64 @@
+package temporal
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+const ExpireOrdersWorkflowName = "ExpireOrdersWorkflow"
+
+const (
+	FindExpiredOrdersActivityName = "FindExpiredOrdersActivity"
+	ExpireOrderActivityName       = "ExpireOrderActivity"
+)
+
+type ExpireOrdersWorkflowInput struct {
+	// TTL is how long order may stay open or pending
+	TTL time.Duration
+	// BatchSize limits orders expired by single run, workflow continues as new while batches are full
+	BatchSize int
+	// ActivityOptions by activity name, see CreateOrderWorkflowInput
+	ActivityOptions map[string]ActivityOptions
+}
+
+type ExpireOrdersWorkflowResult struct {
+	// Expired is count of orders cancelled by the last run
+	Expired int
+}
+
+// ExpireOrdersWorkflow cancels open and pending orders older than TTL and notifies their customers.
+// It is started periodically by schedule, see EnsureExpireOrdersSchedule
+func ExpireOrdersWorkflow(ctx workflow.Context, input ExpireOrdersWorkflowInput) (ExpireOrdersWorkflowResult, error) {
+	createdBefore := workflow.Now(ctx).Add(-input.TTL)
+
+	var orderIDs []uuid.UUID
+	err := executeActivity(ctx, input.ActivityOptions, FindExpiredOrdersActivityName, createdBefore, input.BatchSize).Get(ctx, &orderIDs)
+	if err != nil {
+		return ExpireOrdersWorkflowResult{}, err
+	}
+
+	var result ExpireOrdersWorkflowResult
+	for _, orderID := range orderIDs {
+		var expired ExpireOrderResult
+		err = executeActivity(ctx, input.ActivityOptions, ExpireOrderActivityName, orderID, createdBefore).Get(ctx, &expired)
+		if err != nil {
+			return result, err
+		}
+		if !expired.Expired {
+			continue
+		}
+		result.Expired++
+
+		// order is already cancelled, so failed notification is not a reason to fail expiration of other orders
+		message := "Order " + orderID.String() + " was not completed in time and is cancelled"
+		_ = executeActivity(ctx, input.ActivityOptions, SendNotificationActivityName, expired.CustomerID, message).Get(ctx, nil)
+	}
+
+	// orders found again without being expired would make workflow continue forever, so progress is required
+	if len(orderIDs) == input.BatchSize && result.Expired > 0 {
+		return result, workflow.NewContinueAsNewError(ctx, ExpireOrdersWorkflow, input)
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+const (
+	ExpireOrdersScheduleID   = "expire-orders"
+	expireOrdersWorkflowID   = "expire-orders"
+	expireOrdersScheduleNote = "cancels open and pending orders after their time-to-live"
+)
+
+type ExpireOrdersScheduleConfig struct {
+	// Interval is how often expired orders are looked up
+	Interval time.Duration
+	Input    ExpireOrdersWorkflowInput
+}
+
+// EnsureExpireOrdersSchedule creates schedule of ExpireOrdersWorkflow or updates existing one with config,
+// so it is safe to call on start of every replica
+func EnsureExpireOrdersSchedule(ctx context.Context, c client.Client, config ExpireOrdersScheduleConfig) error {
+	spec := client.ScheduleSpec{
+		Intervals: []client.ScheduleIntervalSpec{{Every: config.Interval}},
+	}
+	action := &client.ScheduleWorkflowAction{
+		ID:        expireOrdersWorkflowID,
+		Workflow:  ExpireOrdersWorkflowName,
+		Args:      []interface{}{config.Input},
+		TaskQueue: TaskQueue,
+	}
+	// run which lasts longer than interval already expires orders of skipped one
+	overlap := enumspb.SCHEDULE_OVERLAP_POLICY_SKIP
+
+	_, err := c.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID:      ExpireOrdersScheduleID,
+		Spec:    spec,
+		Action:  action,
+		Overlap: overlap,
+		Note:    expireOrdersScheduleNote,
+	})
+	if !errors.Is(err, temporal.ErrScheduleAlreadyRunning) {
+		return err
+	}
+
+	handle := c.ScheduleClient().GetHandle(ctx, ExpireOrdersScheduleID)
+	return handle.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			schedule := input.Description.Schedule
+			schedule.Spec = &spec
+			schedule.Action = action
+			if schedule.Policy == nil {
+				schedule.Policy = &client.SchedulePolicies{}
+			}
+			schedule.Policy.Overlap = overlap
+			return &client.ScheduleUpdate{Schedule: &schedule}, nil
+		},
+	})
+}
+
+// DeleteExpireOrdersSchedule stops expiration of orders, missing schedule is not an error
+func DeleteExpireOrdersSchedule(ctx context.Context, c client.Client) error {
+	err := c.ScheduleClient().GetHandle(ctx, ExpireOrdersScheduleID).Delete(ctx)
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	return err
+}
@@ -10,11 +10,16 @@ import (
 )
 
 type WorkflowStarterImpl struct {
-	client client.Client
+	client          client.Client
+	activityOptions map[string]ActivityOptions
 }
 
-func NewWorkflowStarter(c client.Client) *WorkflowStarterImpl {
-	return &WorkflowStarterImpl{client: c}
+// NewWorkflowStarter starts workflows with activityOptions by activity name, activities without options have default ones
+func NewWorkflowStarter(c client.Client, activityOptions map[string]ActivityOptions) *WorkflowStarterImpl {
+	return &WorkflowStarterImpl{
+		client:          c,
+		activityOptions: activityOptions,
+	}
 }
 
 func (s *WorkflowStarterImpl) StartCreateOrderWorkflow(ctx context.Context, order appmodel.Order) (uuid.UUID, error) {
@@ -22,7 +27,10 @@ func (s *WorkflowStarterImpl) StartCreateOrderWorkflow(ctx context.Context, orde
 		ID:        "order-" + uuid.New().String(),
 		TaskQueue: TaskQueue,
 	}
-	input := CreateOrderWorkflowInput{Order: order}
+	input := CreateOrderWorkflowInput{
+		Order:           order,
+		ActivityOptions: s.activityOptions,
+	}
 	we, err := s.client.ExecuteWorkflow(ctx, options, CreateOrderWorkflow, input)
 	if err != nil {
 		return uuid.Nil, err
@@ -31,7 +39,7 @@ func (s *WorkflowStarterImpl) StartCreateOrderWorkflow(ctx context.Context, orde
 	var result CreateOrderWorkflowResult
 	err = we.Get(ctx, &result)
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, quotaExceededError(err)
 	}
 	return result.OrderID, nil
 }
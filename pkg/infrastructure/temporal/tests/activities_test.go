@@ -0,0 +1,390 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	infratemporal "order/pkg/infrastructure/temporal"
+)
+
+func TestActivities(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	userID := uuid.New()
+	productID := uuid.New()
+
+	newEnv := func(uow *inMemoryUnitOfWork) (*testsuite.TestActivityEnvironment, *fakeNotificationService) {
+		notifications := &fakeNotificationService{}
+		env := suite.NewTestActivityEnvironment()
+		env.RegisterActivity(infratemporal.NewActivities(
+			uow,
+			&fakeProductService{prices: map[uuid.UUID]float64{productID: 10}},
+			&fakePaymentService{},
+			notifications,
+			nil,
+			service.NewQuotaChecker(service.QuotaConfig{MaxOpenOrders: 1}),
+		))
+		return env, notifications
+	}
+
+	t.Run("Create order stores open order with history", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		env, _ := newEnv(uow)
+
+		result, err := env.ExecuteActivity(infratemporal.CreateOrderActivityName, appmodel.Order{UserID: userID})
+		require.NoError(t, err)
+		var orderID uuid.UUID
+		require.NoError(t, result.Get(&orderID))
+
+		order := uow.orders[orderID]
+		require.NotNil(t, order)
+		require.Equal(t, userID, order.CustomerID)
+		require.Equal(t, model.Open, order.Status)
+		require.Len(t, uow.events, 1)
+		require.Equal(t, model.OrderCreated{OrderID: orderID, CustomerID: userID}, uow.events[0].Event)
+		require.Equal(t, "temporal:"+infratemporal.CreateOrderActivityName, uow.events[0].Source)
+	})
+
+	t.Run("Create order over quota fails without retry", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		uow.addOrder(userID, model.Pending)
+		env, _ := newEnv(uow)
+
+		_, err := env.ExecuteActivity(infratemporal.CreateOrderActivityName, appmodel.Order{UserID: userID})
+
+		var applicationErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &applicationErr)
+		require.Equal(t, infratemporal.QuotaExceededErrorType, applicationErr.Type())
+		require.True(t, applicationErr.NonRetryable())
+		require.Len(t, uow.orders, 1)
+		require.True(t, uow.customerLocked[userID])
+	})
+
+	t.Run("Add item adds item per quantity", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		orderID := uow.addOrder(userID, model.Open)
+		env, _ := newEnv(uow)
+
+		_, err := env.ExecuteActivity(infratemporal.AddItemActivityName, orderID, productID, 10.0, 3)
+		require.NoError(t, err)
+
+		require.Len(t, uow.orders[orderID].Items, 3)
+		for _, item := range uow.orders[orderID].Items {
+			require.Equal(t, productID, item.ProductID)
+			require.Equal(t, 10.0, item.Price)
+		}
+	})
+
+	t.Run("Add item to missing order is not retryable", func(t *testing.T) {
+		env, _ := newEnv(newInMemoryUnitOfWork())
+
+		_, err := env.ExecuteActivity(infratemporal.AddItemActivityName, uuid.New(), productID, 10.0, 1)
+		requireNonRetryable(t, err, infratemporal.OrderNotFoundErrorType)
+	})
+
+	t.Run("Add item to paid order is not retryable", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		orderID := uow.addOrder(userID, model.Paid)
+		env, _ := newEnv(uow)
+
+		_, err := env.ExecuteActivity(infratemporal.AddItemActivityName, orderID, productID, 10.0, 1)
+		requireNonRetryable(t, err, infratemporal.InvalidOrderStatusErrorType)
+	})
+
+	t.Run("Get product price", func(t *testing.T) {
+		env, _ := newEnv(newInMemoryUnitOfWork())
+
+		result, err := env.ExecuteActivity(infratemporal.GetProductPriceActivityName, productID)
+		require.NoError(t, err)
+		var price float64
+		require.NoError(t, result.Get(&price))
+		require.Equal(t, 10.0, price)
+	})
+
+	t.Run("Price of missing product is not retryable", func(t *testing.T) {
+		env, _ := newEnv(newInMemoryUnitOfWork())
+
+		_, err := env.ExecuteActivity(infratemporal.GetProductPriceActivityName, uuid.New())
+		requireNonRetryable(t, err, infratemporal.ProductNotFoundErrorType)
+	})
+
+	t.Run("Mark order paid moves open order to paid", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		orderID := uow.addOrder(userID, model.Open)
+		env, _ := newEnv(uow)
+
+		_, err := env.ExecuteActivity(infratemporal.MarkOrderPaidActivityName, orderID)
+		require.NoError(t, err)
+		require.Equal(t, model.Paid, uow.orders[orderID].Status)
+		require.Len(t, uow.events, 1)
+		require.Equal(t, model.OrderStatusChanged{
+			OrderID:        orderID,
+			Status:         model.Paid,
+			PreviousStatus: model.Open,
+		}, uow.events[0].Event)
+
+		// retried activity doesn't change paid order again
+		_, err = env.ExecuteActivity(infratemporal.MarkOrderPaidActivityName, orderID)
+		require.NoError(t, err)
+		require.Len(t, uow.events, 1)
+	})
+
+	t.Run("Mark cancelled order paid is not retryable", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		orderID := uow.addOrder(userID, model.Cancelled)
+		env, _ := newEnv(uow)
+
+		_, err := env.ExecuteActivity(infratemporal.MarkOrderPaidActivityName, orderID)
+		requireNonRetryable(t, err, infratemporal.InvalidOrderStatusErrorType)
+		require.Equal(t, model.Cancelled, uow.orders[orderID].Status)
+	})
+
+	t.Run("Expire order cancels open order created before cutoff", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		orderID := uow.addOrder(userID, model.Pending)
+		env, _ := newEnv(uow)
+
+		result, err := env.ExecuteActivity(infratemporal.ExpireOrderActivityName, orderID, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		var expired infratemporal.ExpireOrderResult
+		require.NoError(t, result.Get(&expired))
+
+		require.Equal(t, infratemporal.ExpireOrderResult{Expired: true, CustomerID: userID}, expired)
+		require.Equal(t, model.Cancelled, uow.orders[orderID].Status)
+		require.Len(t, uow.events, 1)
+		require.Equal(t, model.OrderStatusChanged{
+			OrderID:        orderID,
+			Status:         model.Cancelled,
+			PreviousStatus: model.Pending,
+		}, uow.events[0].Event)
+		require.Equal(t, "temporal:"+infratemporal.ExpireOrderActivityName, uow.events[0].Source)
+	})
+
+	t.Run("Expire order keeps paid and recent orders", func(t *testing.T) {
+		uow := newInMemoryUnitOfWork()
+		paidOrderID := uow.addOrder(userID, model.Paid)
+		recentOrderID := uow.addOrder(userID, model.Open)
+		env, _ := newEnv(uow)
+
+		for orderID, createdBefore := range map[uuid.UUID]time.Time{
+			paidOrderID:   time.Now().Add(time.Hour),
+			recentOrderID: time.Now().Add(-time.Hour),
+		} {
+			result, err := env.ExecuteActivity(infratemporal.ExpireOrderActivityName, orderID, createdBefore)
+			require.NoError(t, err)
+			var expired infratemporal.ExpireOrderResult
+			require.NoError(t, result.Get(&expired))
+			require.False(t, expired.Expired)
+		}
+		require.Equal(t, model.Paid, uow.orders[paidOrderID].Status)
+		require.Equal(t, model.Open, uow.orders[recentOrderID].Status)
+		require.Empty(t, uow.events)
+	})
+
+	t.Run("Expire deleted order does nothing", func(t *testing.T) {
+		env, _ := newEnv(newInMemoryUnitOfWork())
+
+		result, err := env.ExecuteActivity(infratemporal.ExpireOrderActivityName, uuid.New(), time.Now())
+		require.NoError(t, err)
+		var expired infratemporal.ExpireOrderResult
+		require.NoError(t, result.Get(&expired))
+		require.False(t, expired.Expired)
+	})
+
+	t.Run("Send notification", func(t *testing.T) {
+		env, notifications := newEnv(newInMemoryUnitOfWork())
+
+		_, err := env.ExecuteActivity(infratemporal.SendNotificationActivityName, userID, "order created")
+		require.NoError(t, err)
+		require.Equal(t, []string{"order created"}, notifications.messages[userID])
+	})
+}
+
+func requireNonRetryable(t *testing.T, err error, errorType string) {
+	t.Helper()
+	var applicationErr *temporal.ApplicationError
+	require.ErrorAs(t, err, &applicationErr)
+	require.True(t, applicationErr.NonRetryable())
+	require.Equal(t, errorType, applicationErr.Type())
+}
+
+var _ service.UnitOfWork = &inMemoryUnitOfWork{}
+
+func newInMemoryUnitOfWork() *inMemoryUnitOfWork {
+	return &inMemoryUnitOfWork{orders: map[uuid.UUID]*model.Order{}, customerLocked: map[uuid.UUID]bool{}}
+}
+
+// inMemoryUnitOfWork keeps changes of failed callback, activities are tested one call at a time
+type inMemoryUnitOfWork struct {
+	orders         map[uuid.UUID]*model.Order
+	events         []model.OrderEventRecord
+	customerLocked map[uuid.UUID]bool
+
+	active bool
+	hooks  []service.PostCommitHook
+}
+
+func (u *inMemoryUnitOfWork) addOrder(customerID uuid.UUID, status model.OrderStatus) uuid.UUID {
+	orderID := uuid.New()
+	u.orders[orderID] = &model.Order{ID: orderID, CustomerID: customerID, Status: status, CreatedAt: time.Now()}
+	return orderID
+}
+
+func (u *inMemoryUnitOfWork) Execute(ctx context.Context, f func(provider service.RepositoryProvider) error) error {
+	u.active = true
+	u.hooks = nil
+	defer func() {
+		u.active = false
+	}()
+
+	err := f(u)
+	if err != nil {
+		return err
+	}
+	// hook errors don't fail committed unit of work
+	for _, hook := range u.hooks {
+		_ = hook(ctx)
+	}
+	return nil
+}
+
+func (u *inMemoryUnitOfWork) AfterCommit(_ context.Context, hook service.PostCommitHook) error {
+	if !u.active {
+		return service.ErrNoActiveUnitOfWork
+	}
+	u.hooks = append(u.hooks, hook)
+	return nil
+}
+
+func (u *inMemoryUnitOfWork) OrderRepository() model.OrderRepository {
+	return (*inMemoryOrderRepository)(u)
+}
+
+func (u *inMemoryUnitOfWork) OrderEventRepository() model.OrderEventRepository {
+	return (*inMemoryOrderEventRepository)(u)
+}
+
+// OrderQuery is not used by tested code
+func (u *inMemoryUnitOfWork) OrderQuery() service.OrderQuery {
+	return nil
+}
+
+func (u *inMemoryUnitOfWork) OrderCountQuery() service.OrderCountQuery {
+	return (*inMemoryOrderCountQuery)(u)
+}
+
+type inMemoryOrderRepository inMemoryUnitOfWork
+
+func (r *inMemoryOrderRepository) NextID(_ context.Context) (uuid.UUID, error) {
+	return uuid.NewV7()
+}
+
+func (r *inMemoryOrderRepository) Store(_ context.Context, order *model.Order) error {
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *inMemoryOrderRepository) Find(_ context.Context, id uuid.UUID) (*model.Order, error) {
+	if order, ok := r.orders[id]; ok && order.DeletedAt == nil {
+		return order, nil
+	}
+	return nil, model.ErrOrderNotFound
+}
+
+func (r *inMemoryOrderRepository) Delete(_ context.Context, id uuid.UUID) error {
+	delete(r.orders, id)
+	return nil
+}
+
+type inMemoryOrderEventRepository inMemoryUnitOfWork
+
+func (r *inMemoryOrderEventRepository) Append(_ context.Context, record *model.OrderEventRecord) error {
+	record.ID = int64(len(r.events) + 1)
+	r.events = append(r.events, *record)
+	return nil
+}
+
+func (r *inMemoryOrderEventRepository) FindByOrderID(_ context.Context, orderID uuid.UUID) ([]model.OrderEventRecord, error) {
+	var records []model.OrderEventRecord
+	for _, record := range r.events {
+		if record.OrderID == orderID {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+type inMemoryOrderCountQuery inMemoryUnitOfWork
+
+func (q *inMemoryOrderCountQuery) LockCustomerOrders(_ context.Context, customerID uuid.UUID) error {
+	q.customerLocked[customerID] = true
+	return nil
+}
+
+func (q *inMemoryOrderCountQuery) CountOpenOrders(_ context.Context, customerID uuid.UUID) (int, error) {
+	count := 0
+	for _, order := range q.orders {
+		if order.CustomerID == customerID && (order.Status == model.Open || order.Status == model.Pending) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *inMemoryOrderCountQuery) CountOrdersSince(_ context.Context, customerID uuid.UUID, since time.Time) (int, time.Time, error) {
+	var (
+		count  int
+		oldest time.Time
+	)
+	for _, order := range q.orders {
+		if order.CustomerID != customerID || order.CreatedAt.Before(since) {
+			continue
+		}
+		count++
+		if oldest.IsZero() || order.CreatedAt.Before(oldest) {
+			oldest = order.CreatedAt
+		}
+	}
+	return count, oldest, nil
+}
+
+type fakeProductService struct {
+	prices map[uuid.UUID]float64
+}
+
+func (s *fakeProductService) GetPrice(_ context.Context, productID uuid.UUID) (float64, error) {
+	price, ok := s.prices[productID]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", service.ErrProductNotFound, productID)
+	}
+	return price, nil
+}
+
+type fakePaymentService struct {
+	err error
+}
+
+func (s *fakePaymentService) ProcessPayment(_ context.Context, _, _ uuid.UUID, _ float64) error {
+	return s.err
+}
+
+type fakeNotificationService struct {
+	messages map[uuid.UUID][]string
+}
+
+func (s *fakeNotificationService) SendNotification(_ context.Context, userID uuid.UUID, message string) error {
+	if s.messages == nil {
+		s.messages = map[uuid.UUID][]string{}
+	}
+	s.messages[userID] = append(s.messages[userID], message)
+	return nil
+}
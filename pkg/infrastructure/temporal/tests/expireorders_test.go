@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	infratemporal "order/pkg/infrastructure/temporal"
+)
+
+func TestExpireOrdersWorkflow(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	customerID := uuid.New()
+	firstOrderID, secondOrderID := uuid.New(), uuid.New()
+	input := infratemporal.ExpireOrdersWorkflowInput{
+		TTL:       24 * time.Hour,
+		BatchSize: 10,
+	}
+
+	newEnv := func() *testsuite.TestWorkflowEnvironment {
+		env := suite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(&infratemporal.Activities{})
+		return env
+	}
+
+	t.Run("Expired orders are cancelled and customers notified", func(t *testing.T) {
+		env := newEnv()
+		env.OnActivity(infratemporal.FindExpiredOrdersActivityName, mock.Anything, mock.Anything, 10).
+			Return([]uuid.UUID{firstOrderID, secondOrderID}, nil).
+			Once()
+		env.OnActivity(infratemporal.ExpireOrderActivityName, mock.Anything, firstOrderID, mock.Anything).
+			Return(infratemporal.ExpireOrderResult{Expired: true, CustomerID: customerID}, nil).
+			Once()
+		// second order is paid after it was found
+		env.OnActivity(infratemporal.ExpireOrderActivityName, mock.Anything, secondOrderID, mock.Anything).
+			Return(infratemporal.ExpireOrderResult{}, nil).
+			Once()
+		env.OnActivity(infratemporal.SendNotificationActivityName, mock.Anything, customerID, mock.Anything).Return(nil).Once()
+
+		env.ExecuteWorkflow(infratemporal.ExpireOrdersWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		var result infratemporal.ExpireOrdersWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		require.Equal(t, 1, result.Expired)
+		env.AssertExpectations(t)
+	})
+
+	t.Run("Orders are expired by time-to-live from workflow time", func(t *testing.T) {
+		env := newEnv()
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		env.SetStartTime(now)
+		env.OnActivity(infratemporal.FindExpiredOrdersActivityName, mock.Anything, now.Add(-input.TTL), 10).
+			Return([]uuid.UUID{}, nil).
+			Once()
+
+		env.ExecuteWorkflow(infratemporal.ExpireOrdersWorkflow, input)
+
+		require.NoError(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+	})
+
+	t.Run("Notification failure doesn't stop expiration", func(t *testing.T) {
+		env := newEnv()
+		env.OnActivity(infratemporal.FindExpiredOrdersActivityName, mock.Anything, mock.Anything, 10).
+			Return([]uuid.UUID{firstOrderID, secondOrderID}, nil)
+		env.OnActivity(infratemporal.ExpireOrderActivityName, mock.Anything, mock.Anything, mock.Anything).
+			Return(infratemporal.ExpireOrderResult{Expired: true, CustomerID: customerID}, nil).
+			Times(2)
+		env.OnActivity(infratemporal.SendNotificationActivityName, mock.Anything, customerID, mock.Anything).
+			Return(temporal.NewNonRetryableApplicationError("notification service is down", "NotificationFailed", nil))
+
+		env.ExecuteWorkflow(infratemporal.ExpireOrdersWorkflow, input)
+
+		require.NoError(t, env.GetWorkflowError())
+		var result infratemporal.ExpireOrdersWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		require.Equal(t, 2, result.Expired)
+		env.AssertExpectations(t)
+	})
+
+	t.Run("Full batch continues as new", func(t *testing.T) {
+		env := newEnv()
+		batchInput := input
+		batchInput.BatchSize = 2
+		env.OnActivity(infratemporal.FindExpiredOrdersActivityName, mock.Anything, mock.Anything, 2).
+			Return([]uuid.UUID{firstOrderID, secondOrderID}, nil).
+			Once()
+		env.OnActivity(infratemporal.ExpireOrderActivityName, mock.Anything, mock.Anything, mock.Anything).
+			Return(infratemporal.ExpireOrderResult{Expired: true, CustomerID: customerID}, nil).
+			Times(2)
+		env.OnActivity(infratemporal.SendNotificationActivityName, mock.Anything, customerID, mock.Anything).Return(nil).Times(2)
+
+		env.ExecuteWorkflow(infratemporal.ExpireOrdersWorkflow, batchInput)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.True(t, workflow.IsContinueAsNewError(env.GetWorkflowError()))
+		env.AssertExpectations(t)
+	})
+
+	t.Run("Full batch without expired orders doesn't continue as new", func(t *testing.T) {
+		env := newEnv()
+		batchInput := input
+		batchInput.BatchSize = 2
+		env.OnActivity(infratemporal.FindExpiredOrdersActivityName, mock.Anything, mock.Anything, 2).
+			Return([]uuid.UUID{firstOrderID, secondOrderID}, nil).
+			Once()
+		env.OnActivity(infratemporal.ExpireOrderActivityName, mock.Anything, mock.Anything, mock.Anything).
+			Return(infratemporal.ExpireOrderResult{}, nil).
+			Times(2)
+
+		env.ExecuteWorkflow(infratemporal.ExpireOrdersWorkflow, batchInput)
+
+		require.NoError(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+	})
+}
+
+func TestPaidOrderIsNotExpired(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	userID, productID := uuid.New(), uuid.New()
+	uow := newInMemoryUnitOfWork()
+	notifications := &fakeNotificationService{}
+	activities := infratemporal.NewActivities(
+		uow,
+		&fakeProductService{prices: map[uuid.UUID]float64{productID: 10}},
+		&fakePaymentService{},
+		notifications,
+		(*inMemoryExpiredOrderQuery)(uow),
+		service.NewQuotaChecker(service.QuotaConfig{}),
+	)
+
+	createEnv := suite.NewTestWorkflowEnvironment()
+	createEnv.RegisterActivity(activities)
+	createEnv.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, infratemporal.CreateOrderWorkflowInput{
+		Order: appmodel.Order{UserID: userID, Items: []appmodel.OrderItem{{ProductID: productID, Quantity: 1}}},
+	})
+	require.NoError(t, createEnv.GetWorkflowError())
+	var created infratemporal.CreateOrderWorkflowResult
+	require.NoError(t, createEnv.GetWorkflowResult(&created))
+	require.Equal(t, model.Paid, uow.orders[created.OrderID].Status)
+
+	// sweep runs after time-to-live of order has passed
+	expireEnv := suite.NewTestWorkflowEnvironment()
+	expireEnv.RegisterActivity(activities)
+	expireEnv.SetStartTime(time.Now().Add(48 * time.Hour))
+	expireEnv.ExecuteWorkflow(infratemporal.ExpireOrdersWorkflow, infratemporal.ExpireOrdersWorkflowInput{TTL: 24 * time.Hour, BatchSize: 10})
+	require.NoError(t, expireEnv.GetWorkflowError())
+	var expired infratemporal.ExpireOrdersWorkflowResult
+	require.NoError(t, expireEnv.GetWorkflowResult(&expired))
+
+	require.Zero(t, expired.Expired)
+	require.Equal(t, model.Paid, uow.orders[created.OrderID].Status)
+	require.Len(t, notifications.messages[userID], 1, "only creation is notified")
+}
+
+type inMemoryExpiredOrderQuery inMemoryUnitOfWork
+
+func (q *inMemoryExpiredOrderQuery) FindExpiredOrders(_ context.Context, createdBefore time.Time, limit int) ([]uuid.UUID, error) {
+	var orderIDs []uuid.UUID
+	for orderID, order := range q.orders {
+		if (order.Status == model.Open || order.Status == model.Pending) && order.CreatedAt.Before(createdBefore) && len(orderIDs) < limit {
+			orderIDs = append(orderIDs, orderID)
+		}
+	}
+	return orderIDs, nil
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/worker"
+
+	infratemporal "order/pkg/infrastructure/temporal"
+)
+
+// TestWorkflowReplay replays histories recorded with every version of workflow code,
+// non-determinism error means change breaks workflows started before it and is not guarded with workflow.GetVersion.
+// History of new version is recorded with `temporal workflow show --workflow-id <id> --output json`
+// and stored as testdata/histories/<workflow>-v<version>-<case>.json next to histories of previous versions
+func TestWorkflowReplay(t *testing.T) {
+	histories, err := filepath.Glob(filepath.Join("testdata", "histories", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, histories)
+
+	for _, history := range histories {
+		t.Run(filepath.Base(history), func(t *testing.T) {
+			replayer := worker.NewWorkflowReplayer()
+			replayer.RegisterWorkflow(infratemporal.CreateOrderWorkflow)
+			replayer.RegisterWorkflow(infratemporal.ExpireOrdersWorkflow)
+
+			require.NoError(t, replayer.ReplayWorkflowHistoryFromJSONFile(nil, history))
+		})
+	}
+}
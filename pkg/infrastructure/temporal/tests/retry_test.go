@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	appmodel "order/pkg/application/model"
+	"order/pkg/application/service"
+	infratemporal "order/pkg/infrastructure/temporal"
+)
+
+func TestActivityErrorClassification(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+
+	t.Run("Declined payment is not retryable", func(t *testing.T) {
+		env := suite.NewTestActivityEnvironment()
+		env.RegisterActivity(infratemporal.NewActivities(nil, nil, &fakePaymentService{
+			err: fmt.Errorf("%w: insufficient funds", service.ErrPaymentDeclined),
+		}, nil, nil, nil))
+
+		_, err := env.ExecuteActivity(infratemporal.ProcessPaymentActivityName, uuid.New(), uuid.New(), 10.0)
+
+		var applicationErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &applicationErr)
+		require.True(t, applicationErr.NonRetryable())
+		require.Equal(t, infratemporal.PaymentDeclinedErrorType, applicationErr.Type())
+	})
+
+	t.Run("Invalid payment request is not retryable and not declined", func(t *testing.T) {
+		env := suite.NewTestActivityEnvironment()
+		env.RegisterActivity(infratemporal.NewActivities(nil, nil, &fakePaymentService{
+			err: fmt.Errorf("%w: amount must be positive", service.ErrInvalidPaymentRequest),
+		}, nil, nil, nil))
+
+		_, err := env.ExecuteActivity(infratemporal.ProcessPaymentActivityName, uuid.New(), uuid.New(), 10.0)
+
+		var applicationErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &applicationErr)
+		require.True(t, applicationErr.NonRetryable())
+		require.Equal(t, infratemporal.InvalidPaymentRequestErrorType, applicationErr.Type())
+	})
+
+	t.Run("Unavailable payment service is retryable", func(t *testing.T) {
+		env := suite.NewTestActivityEnvironment()
+		env.RegisterActivity(infratemporal.NewActivities(nil, nil, &fakePaymentService{
+			err: errors.New("payment service is unavailable"),
+		}, nil, nil, nil))
+
+		_, err := env.ExecuteActivity(infratemporal.ProcessPaymentActivityName, uuid.New(), uuid.New(), 10.0)
+
+		var applicationErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &applicationErr)
+		require.False(t, applicationErr.NonRetryable())
+	})
+}
+
+func TestCreateOrderWorkflowRetryPolicy(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	order := appmodel.Order{
+		UserID: uuid.New(),
+		Items:  []appmodel.OrderItem{{ProductID: uuid.New(), Quantity: 1}},
+	}
+	options := map[string]infratemporal.ActivityOptions{
+		infratemporal.GetProductPriceActivityName: {
+			StartToCloseTimeout: time.Second,
+			MaximumAttempts:     3,
+		},
+	}
+
+	t.Run("Missing product fails workflow without retries", func(t *testing.T) {
+		env := suite.NewTestWorkflowEnvironment()
+		activities := &infratemporal.Activities{}
+		env.RegisterActivity(activities)
+		env.OnActivity(infratemporal.CreateOrderActivityName, mock.Anything, mock.Anything).Return(uuid.New(), nil)
+		env.OnActivity(infratemporal.GetProductPriceActivityName, mock.Anything, mock.Anything).
+			Return(0.0, temporal.NewNonRetryableApplicationError("product not found", infratemporal.ProductNotFoundErrorType, nil)).
+			Once()
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, infratemporal.CreateOrderWorkflowInput{Order: order, ActivityOptions: options})
+
+		require.True(t, env.IsWorkflowCompleted())
+		var applicationErr *temporal.ApplicationError
+		require.ErrorAs(t, env.GetWorkflowError(), &applicationErr)
+		require.Equal(t, infratemporal.ProductNotFoundErrorType, applicationErr.Type())
+		env.AssertExpectations(t)
+	})
+
+	t.Run("Transient failure is retried up to maximum attempts", func(t *testing.T) {
+		env := suite.NewTestWorkflowEnvironment()
+		activities := &infratemporal.Activities{}
+		env.RegisterActivity(activities)
+		env.OnActivity(infratemporal.CreateOrderActivityName, mock.Anything, mock.Anything).Return(uuid.New(), nil)
+		env.OnActivity(infratemporal.GetProductPriceActivityName, mock.Anything, mock.Anything).
+			Return(0.0, errors.New("product service is unavailable")).
+			Times(3)
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, infratemporal.CreateOrderWorkflowInput{Order: order, ActivityOptions: options})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.Error(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+	})
+}
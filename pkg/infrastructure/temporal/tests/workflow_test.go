@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	appmodel "order/pkg/application/model"
+	infratemporal "order/pkg/infrastructure/temporal"
+)
+
+func TestCreateOrderWorkflow(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	userID := uuid.New()
+	orderID := uuid.New()
+	firstProductID, secondProductID := uuid.New(), uuid.New()
+	input := infratemporal.CreateOrderWorkflowInput{
+		Order: appmodel.Order{
+			UserID: userID,
+			Items: []appmodel.OrderItem{
+				{ProductID: firstProductID, Quantity: 2},
+				{ProductID: secondProductID, Quantity: 1},
+			},
+		},
+	}
+
+	newEnv := func() *testsuite.TestWorkflowEnvironment {
+		env := suite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(&infratemporal.Activities{})
+		return env
+	}
+	onPrices := func(env *testsuite.TestWorkflowEnvironment) {
+		env.OnActivity(infratemporal.GetProductPriceActivityName, mock.Anything, firstProductID).Return(10.0, nil).Once()
+		env.OnActivity(infratemporal.GetProductPriceActivityName, mock.Anything, secondProductID).Return(5.0, nil).Once()
+	}
+	onOrder := func(env *testsuite.TestWorkflowEnvironment) {
+		env.OnActivity(infratemporal.CreateOrderActivityName, mock.Anything, input.Order).Return(orderID, nil).Once()
+		env.OnActivity(infratemporal.AddItemActivityName, mock.Anything, orderID, firstProductID, 10.0, 2).Return(nil).Once()
+		env.OnActivity(infratemporal.AddItemActivityName, mock.Anything, orderID, secondProductID, 5.0, 1).Return(nil).Once()
+	}
+	onPaid := func(env *testsuite.TestWorkflowEnvironment) {
+		env.OnActivity(infratemporal.ProcessPaymentActivityName, mock.Anything, userID, orderID, 25.0).Return(nil).Once()
+		env.OnActivity(infratemporal.MarkOrderPaidActivityName, mock.Anything, orderID).Return(nil).Once()
+	}
+
+	t.Run("Order is created and paid", func(t *testing.T) {
+		env := newEnv()
+		onPrices(env)
+		onOrder(env)
+		onPaid(env)
+		env.OnActivity(infratemporal.SendNotificationActivityName, mock.Anything, userID, mock.Anything).Return(nil).Once()
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		var result infratemporal.CreateOrderWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		require.Equal(t, orderID, result.OrderID)
+		env.AssertExpectations(t)
+	})
+
+	t.Run("Order is created before price of every item is looked up", func(t *testing.T) {
+		env := newEnv()
+		var calls []string
+		env.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, _ converter.EncodedValues) {
+			calls = append(calls, info.ActivityType.Name)
+		})
+		onPrices(env)
+		onOrder(env)
+		onPaid(env)
+		env.OnActivity(infratemporal.SendNotificationActivityName, mock.Anything, userID, mock.Anything).Return(nil).Once()
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, input)
+
+		require.NoError(t, env.GetWorkflowError())
+		require.Equal(t, []string{
+			infratemporal.CreateOrderActivityName,
+			infratemporal.GetProductPriceActivityName,
+			infratemporal.AddItemActivityName,
+			infratemporal.GetProductPriceActivityName,
+			infratemporal.AddItemActivityName,
+			infratemporal.ProcessPaymentActivityName,
+			infratemporal.MarkOrderPaidActivityName,
+			infratemporal.SendNotificationActivityName,
+		}, calls)
+	})
+
+	t.Run("Workflow started before mark-order-paid change leaves order open", func(t *testing.T) {
+		env := newEnv()
+		env.OnGetVersion("mark-order-paid", workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+		onPrices(env)
+		onOrder(env)
+		env.OnActivity(infratemporal.ProcessPaymentActivityName, mock.Anything, userID, orderID, 25.0).Return(nil).Once()
+		env.OnActivity(infratemporal.SendNotificationActivityName, mock.Anything, userID, mock.Anything).Return(nil).Once()
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, input)
+
+		require.NoError(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+		env.AssertActivityNotCalled(t, infratemporal.MarkOrderPaidActivityName, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Price lookup failure fails workflow without payment", func(t *testing.T) {
+		env := newEnv()
+		env.OnActivity(infratemporal.CreateOrderActivityName, mock.Anything, input.Order).Return(orderID, nil).Once()
+		env.OnActivity(infratemporal.GetProductPriceActivityName, mock.Anything, firstProductID).Return(10.0, nil).Once()
+		env.OnActivity(infratemporal.AddItemActivityName, mock.Anything, orderID, firstProductID, 10.0, 2).Return(nil).Once()
+		env.OnActivity(infratemporal.GetProductPriceActivityName, mock.Anything, secondProductID).
+			Return(0.0, temporal.NewNonRetryableApplicationError("product not found", infratemporal.ProductNotFoundErrorType, nil)).
+			Once()
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		var applicationErr *temporal.ApplicationError
+		require.ErrorAs(t, env.GetWorkflowError(), &applicationErr)
+		require.Equal(t, infratemporal.ProductNotFoundErrorType, applicationErr.Type())
+		env.AssertExpectations(t)
+		env.AssertActivityNotCalled(t, infratemporal.ProcessPaymentActivityName, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Payment failure fails workflow without notification", func(t *testing.T) {
+		env := newEnv()
+		onPrices(env)
+		onOrder(env)
+		env.OnActivity(infratemporal.ProcessPaymentActivityName, mock.Anything, userID, orderID, 25.0).
+			Return(temporal.NewNonRetryableApplicationError("payment declined", infratemporal.PaymentDeclinedErrorType, nil)).
+			Once()
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		var applicationErr *temporal.ApplicationError
+		require.ErrorAs(t, env.GetWorkflowError(), &applicationErr)
+		require.Equal(t, infratemporal.PaymentDeclinedErrorType, applicationErr.Type())
+		env.AssertExpectations(t)
+		env.AssertActivityNotCalled(t, infratemporal.MarkOrderPaidActivityName, mock.Anything, mock.Anything)
+		env.AssertActivityNotCalled(t, infratemporal.SendNotificationActivityName, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Notification failure doesn't fail workflow", func(t *testing.T) {
+		env := newEnv()
+		onPrices(env)
+		onOrder(env)
+		onPaid(env)
+		env.OnActivity(infratemporal.SendNotificationActivityName, mock.Anything, userID, mock.Anything).
+			Return(temporal.NewNonRetryableApplicationError("notification service is down", "NotificationFailed", nil))
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+		var result infratemporal.CreateOrderWorkflowResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		require.Equal(t, orderID, result.OrderID)
+	})
+
+	t.Run("Payment timeout fails workflow after maximum attempts", func(t *testing.T) {
+		env := newEnv()
+		onPrices(env)
+		onOrder(env)
+		env.OnActivity(infratemporal.ProcessPaymentActivityName, mock.Anything, userID, orderID, 25.0).
+			Return(func(ctx context.Context, _, _ uuid.UUID, _ float64) error {
+				// payment service hangs until activity times out
+				<-ctx.Done()
+				return ctx.Err()
+			}).
+			Times(2)
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, infratemporal.CreateOrderWorkflowInput{
+			Order: input.Order,
+			ActivityOptions: map[string]infratemporal.ActivityOptions{
+				infratemporal.ProcessPaymentActivityName: {
+					StartToCloseTimeout: 100 * time.Millisecond,
+					MaximumAttempts:     2,
+				},
+			},
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		var timeoutErr *temporal.TimeoutError
+		require.ErrorAs(t, env.GetWorkflowError(), &timeoutErr)
+		env.AssertActivityNotCalled(t, infratemporal.SendNotificationActivityName, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed payment is not retried", func(t *testing.T) {
+		env := newEnv()
+		onPrices(env)
+		onOrder(env)
+		// payment may have been charged even if call failed, so it is attempted once
+		env.OnActivity(infratemporal.ProcessPaymentActivityName, mock.Anything, userID, orderID, 25.0).
+			Return(errors.New("payment service is unavailable")).
+			Once()
+
+		env.ExecuteWorkflow(infratemporal.CreateOrderWorkflow, input)
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.Error(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+		env.AssertActivityNotCalled(t, infratemporal.SendNotificationActivityName, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
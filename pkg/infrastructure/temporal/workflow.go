@@ -6,6 +6,7 @@ import (
 	appmodel "order/pkg/application/model"
 
 	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -14,23 +15,70 @@ const (
 	CreateOrderWorkflowName = "CreateOrderWorkflow"
 )
 
+const (
+	CreateOrderActivityName      = "CreateOrderActivity"
+	GetProductPriceActivityName  = "GetProductPriceActivity"
+	AddItemActivityName          = "AddItemActivity"
+	ProcessPaymentActivityName   = "ProcessPaymentActivity"
+	MarkOrderPaidActivityName    = "MarkOrderPaidActivity"
+	SendNotificationActivityName = "SendNotificationActivity"
+)
+
+// markOrderPaidChangeID guards marking order paid, workflows started before it leave paid order open
+const markOrderPaidChangeID = "mark-order-paid"
+
+// defaultActivityTimeout is used for activities without options, like ones of workflows started before options were added
+const defaultActivityTimeout = time.Minute
+
+// defaultActivityOptions are used for activities without options instead of unlimited retries.
+// Payment request has no idempotency key, so payment which succeeded but timed out would be charged again on retry
+var defaultActivityOptions = map[string]ActivityOptions{
+	ProcessPaymentActivityName: {
+		StartToCloseTimeout: defaultActivityTimeout,
+		MaximumAttempts:     1,
+	},
+}
+
+// defaultMaximumAttempts is used for activity with options which don't set maximum attempts
+const defaultMaximumAttempts = 5
+
+// DefaultMaximumAttempts is maximum attempts of activity for options which don't set them, payment is attempted once
+func DefaultMaximumAttempts(name string) int32 {
+	if options, ok := defaultActivityOptions[name]; ok {
+		return options.MaximumAttempts
+	}
+	return defaultMaximumAttempts
+}
+
+// ActivityOptions are timeouts and retry policy of activity, zero values fall back to Temporal defaults
+type ActivityOptions struct {
+	StartToCloseTimeout    time.Duration
+	ScheduleToCloseTimeout time.Duration
+	InitialInterval        time.Duration
+	BackoffCoefficient     float64
+	MaximumInterval        time.Duration
+	// MaximumAttempts includes the first attempt, zero means unlimited attempts
+	MaximumAttempts int32
+}
+
 type CreateOrderWorkflowInput struct {
 	Order appmodel.Order
+	// ActivityOptions by activity name are passed with input, so running workflow keeps options it was started with
+	ActivityOptions map[string]ActivityOptions
 }
 
 type CreateOrderWorkflowResult struct {
 	OrderID uuid.UUID
 }
 
+// CreateOrderWorkflow creates order, adds items with current prices, pays for order, marks it paid and notifies customer.
+// Every change of commands issued by workflow is guarded with workflow.GetVersion under its own change ID,
+// replay test replays histories recorded with every version. Old branch may be removed only when
+// no running workflow and no replay history has its version
 func CreateOrderWorkflow(ctx workflow.Context, input CreateOrderWorkflowInput) (CreateOrderWorkflowResult, error) {
-	ao := workflow.ActivityOptions{
-		StartToCloseTimeout: time.Minute,
-	}
-	ctx = workflow.WithActivityOptions(ctx, ao)
-
 	var orderID uuid.UUID
 	// 1. Create Order in DB (Pending)
-	err := workflow.ExecuteActivity(ctx, "CreateOrderActivity", input.Order).Get(ctx, &orderID)
+	err := executeActivity(ctx, input.ActivityOptions, CreateOrderActivityName, input.Order).Get(ctx, &orderID)
 	if err != nil {
 		return CreateOrderWorkflowResult{}, err
 	}
@@ -39,26 +87,66 @@ func CreateOrderWorkflow(ctx workflow.Context, input CreateOrderWorkflowInput) (
 	var totalAmount float64
 	for _, item := range input.Order.Items {
 		var price float64
-		err = workflow.ExecuteActivity(ctx, "GetProductPriceActivity", item.ProductID).Get(ctx, &price)
+		err = executeActivity(ctx, input.ActivityOptions, GetProductPriceActivityName, item.ProductID).Get(ctx, &price)
 		if err != nil {
 			return CreateOrderWorkflowResult{}, err
 		}
 		totalAmount += price * float64(item.Quantity)
 
-		err = workflow.ExecuteActivity(ctx, "AddItemActivity", orderID, item.ProductID, price, item.Quantity).Get(ctx, nil)
+		err = executeActivity(ctx, input.ActivityOptions, AddItemActivityName, orderID, item.ProductID, price, item.Quantity).Get(ctx, nil)
 		if err != nil {
 			return CreateOrderWorkflowResult{}, err
 		}
 	}
 
 	// 3. Process Payment
-	err = workflow.ExecuteActivity(ctx, "ProcessPaymentActivity", input.Order.UserID, orderID, totalAmount).Get(ctx, nil)
+	err = executeActivity(ctx, input.ActivityOptions, ProcessPaymentActivityName, input.Order.UserID, orderID, totalAmount).Get(ctx, nil)
 	if err != nil {
 		return CreateOrderWorkflowResult{}, err
 	}
+	if workflow.GetVersion(ctx, markOrderPaidChangeID, workflow.DefaultVersion, 1) == 1 {
+		err = executeActivity(ctx, input.ActivityOptions, MarkOrderPaidActivityName, orderID).Get(ctx, nil)
+		if err != nil {
+			return CreateOrderWorkflowResult{}, err
+		}
+	}
 
 	// 4. Send Notification
-	_ = workflow.ExecuteActivity(ctx, "SendNotificationActivity", input.Order.UserID, "Order created via Temporal").Get(ctx, nil)
+	_ = executeActivity(ctx, input.ActivityOptions, SendNotificationActivityName, input.Order.UserID, "Order created via Temporal").Get(ctx, nil)
 
 	return CreateOrderWorkflowResult{OrderID: orderID}, nil
 }
+
+func executeActivity(ctx workflow.Context, options map[string]ActivityOptions, name string, args ...interface{}) workflow.Future {
+	return workflow.ExecuteActivity(withActivityOptions(ctx, options, name), name, args...)
+}
+
+func withActivityOptions(ctx workflow.Context, options map[string]ActivityOptions, name string) workflow.Context {
+	activityOptions, ok := options[name]
+	if !ok {
+		activityOptions, ok = defaultActivityOptions[name]
+	}
+	if !ok {
+		return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+			StartToCloseTimeout: defaultActivityTimeout,
+			RetryPolicy: &temporal.RetryPolicy{
+				NonRetryableErrorTypes: nonRetryableErrorTypes,
+			},
+		})
+	}
+	if activityOptions.StartToCloseTimeout == 0 && activityOptions.ScheduleToCloseTimeout == 0 {
+		// Temporal rejects activity without any timeout
+		activityOptions.StartToCloseTimeout = defaultActivityTimeout
+	}
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout:    activityOptions.StartToCloseTimeout,
+		ScheduleToCloseTimeout: activityOptions.ScheduleToCloseTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        activityOptions.InitialInterval,
+			BackoffCoefficient:     activityOptions.BackoffCoefficient,
+			MaximumInterval:        activityOptions.MaximumInterval,
+			MaximumAttempts:        activityOptions.MaximumAttempts,
+			NonRetryableErrorTypes: nonRetryableErrorTypes,
+		},
+	})
+}
@@ -0,0 +1,171 @@
+package tlscredentials
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+)
+
+type ServerConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables verification of client certificates signed by given CA bundle
+	ClientCAFile string
+	// RequireClientCert rejects clients without certificate, works only with ClientCAFile
+	RequireClientCert bool
+}
+
+type ClientConfig struct {
+	// CAFile is CA bundle to verify server, system roots are used if it is empty
+	CAFile string
+	// CertFile and KeyFile are client certificate for mTLS, optional
+	CertFile string
+	KeyFile  string
+	// ServerName overrides name verified in server certificate
+	ServerName string
+}
+
+// NewServerCredentials returns gRPC server credentials reloading certificates when files change on disk
+func NewServerCredentials(config ServerConfig, logger logging.Logger) (credentials.TransportCredentials, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("server TLS requires cert and key files")
+	}
+	if config.RequireClientCert && config.ClientCAFile == "" {
+		return nil, errors.New("client certificate can't be required without client CA file")
+	}
+
+	return newReloadingCredentials(
+		func() (*tls.Config, error) {
+			return serverTLSConfig(config)
+		},
+		logger,
+		config.CertFile, config.KeyFile, config.ClientCAFile,
+	)
+}
+
+// NewClientCredentials returns gRPC client credentials reloading certificates when files change on disk
+func NewClientCredentials(config ClientConfig, logger logging.Logger) (credentials.TransportCredentials, error) {
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, errors.New("client certificate requires both cert and key files")
+	}
+
+	return newReloadingCredentials(
+		func() (*tls.Config, error) {
+			return clientTLSConfig(config)
+		},
+		logger,
+		config.CAFile, config.CertFile, config.KeyFile,
+	)
+}
+
+func serverTLSConfig(config ServerConfig) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+	}
+	if config.ClientCAFile != "" {
+		tlsConfig.ClientCAs, err = loadCertPool(config.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if config.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return tlsConfig, nil
+}
+
+func clientTLSConfig(config ClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: config.ServerName,
+	}
+	if config.CAFile != "" {
+		var err error
+		tlsConfig.RootCAs, err = loadCertPool(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// reloadingCredentials builds TLS credentials for every handshake from config
+// that is reloaded only when watched files change
+type reloadingCredentials struct {
+	config     *reloadingConfig
+	serverName string
+}
+
+func newReloadingCredentials(
+	load func() (*tls.Config, error),
+	logger logging.Logger,
+	files ...string,
+) (*reloadingCredentials, error) {
+	config, err := newReloadingConfig(load, logger, files...)
+	if err != nil {
+		return nil, err
+	}
+	return &reloadingCredentials{config: config}, nil
+}
+
+func (c *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (c *reloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ServerHandshake(conn)
+}
+
+func (c *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return c.current().Info()
+}
+
+func (c *reloadingCredentials) Clone() credentials.TransportCredentials {
+	clone := *c
+	return &clone
+}
+
+// nolint:staticcheck
+func (c *reloadingCredentials) OverrideServerName(serverName string) error {
+	c.serverName = serverName
+	return nil
+}
+
+func (c *reloadingCredentials) current() credentials.TransportCredentials {
+	tlsConfig := c.config.Get()
+	if c.serverName != "" {
+		tlsConfig.ServerName = c.serverName
+	}
+	return credentials.NewTLS(tlsConfig)
+}
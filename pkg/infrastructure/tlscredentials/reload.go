@@ -0,0 +1,96 @@
+package tlscredentials
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	"github.com/pkg/errors"
+)
+
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// reloadingConfig checks watched files on every Get and rebuilds TLS config when any of them changed,
+// previous config stays in use until files are valid again, e.g. certificate is already replaced but key is not yet
+type reloadingConfig struct {
+	load   func() (*tls.Config, error)
+	logger logging.Logger
+	files  []string
+
+	mu       sync.Mutex
+	config   *tls.Config
+	versions []fileVersion
+}
+
+func newReloadingConfig(load func() (*tls.Config, error), logger logging.Logger, files ...string) (*reloadingConfig, error) {
+	c := &reloadingConfig{
+		load:   load,
+		logger: logger,
+	}
+	for _, file := range files {
+		if file != "" {
+			c.files = append(c.files, file)
+		}
+	}
+
+	versions, err := c.fileVersions()
+	if err != nil {
+		return nil, err
+	}
+	c.config, err = load()
+	if err != nil {
+		return nil, err
+	}
+	c.versions = versions
+	return c, nil
+}
+
+// Get returns copy of actual config that can be modified by caller
+func (c *reloadingConfig) Get() *tls.Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versions, err := c.fileVersions()
+	if err != nil {
+		c.logger.Error(err, "failed to check TLS files, previous certificates are used")
+		return c.config.Clone()
+	}
+	if c.changed(versions) {
+		// failed files are not reloaded again until next change
+		c.versions = versions
+		config, err := c.load()
+		if err != nil {
+			c.logger.Error(err, "failed to reload TLS files, previous certificates are used")
+			return c.config.Clone()
+		}
+		c.config = config
+		c.logger.Info("TLS certificates reloaded")
+	}
+	return c.config.Clone()
+}
+
+func (c *reloadingConfig) fileVersions() ([]fileVersion, error) {
+	versions := make([]fileVersion, 0, len(c.files))
+	for _, file := range c.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		versions = append(versions, fileVersion{modTime: info.ModTime(), size: info.Size()})
+	}
+	return versions, nil
+}
+
+func (c *reloadingConfig) changed(versions []fileVersion) bool {
+	for i, version := range versions {
+		if !version.modTime.Equal(c.versions[i].modTime) || version.size != c.versions[i].size {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/logging"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"order/pkg/infrastructure/tlscredentials"
+)
+
+func TestCredentials(t *testing.T) {
+	logger := logging.NewJSONLogger(&logging.Config{AppName: "test"})
+	dir := t.TempDir()
+	file := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	serverCA := newCA(t)
+	clientCA := newCA(t)
+	serverCA.writeCert(t, file("server-ca.pem"))
+	clientCA.writeCert(t, file("client-ca.pem"))
+	serverCA.issue(t, "localhost", file("server.pem"), file("server-key.pem"))
+	clientCA.issue(t, "client", file("client.pem"), file("client-key.pem"))
+
+	serverCredentials, err := tlscredentials.NewServerCredentials(tlscredentials.ServerConfig{
+		CertFile:          file("server.pem"),
+		KeyFile:           file("server-key.pem"),
+		ClientCAFile:      file("client-ca.pem"),
+		RequireClientCert: true,
+	}, logger)
+	require.NoError(t, err)
+	address := serve(t, serverCredentials)
+
+	t.Run("Client with certificate passes mTLS", func(t *testing.T) {
+		clientCredentials, err := tlscredentials.NewClientCredentials(tlscredentials.ClientConfig{
+			CAFile:     file("server-ca.pem"),
+			CertFile:   file("client.pem"),
+			KeyFile:    file("client-key.pem"),
+			ServerName: "localhost",
+		}, logger)
+		require.NoError(t, err)
+		require.NoError(t, check(t, address, clientCredentials))
+	})
+
+	t.Run("Client without certificate is rejected", func(t *testing.T) {
+		clientCredentials, err := tlscredentials.NewClientCredentials(tlscredentials.ClientConfig{
+			CAFile:     file("server-ca.pem"),
+			ServerName: "localhost",
+		}, logger)
+		require.NoError(t, err)
+		require.Error(t, check(t, address, clientCredentials))
+	})
+
+	t.Run("Server certificate is reloaded when files change", func(t *testing.T) {
+		newServerCA := newCA(t)
+		newServerCA.writeCert(t, file("new-server-ca.pem"))
+		clientCredentials, err := tlscredentials.NewClientCredentials(tlscredentials.ClientConfig{
+			CAFile:     file("new-server-ca.pem"),
+			CertFile:   file("client.pem"),
+			KeyFile:    file("client-key.pem"),
+			ServerName: "localhost",
+		}, logger)
+		require.NoError(t, err)
+		require.Error(t, check(t, address, clientCredentials))
+
+		newServerCA.issue(t, "localhost", file("server.pem"), file("server-key.pem"))
+		require.NoError(t, check(t, address, clientCredentials))
+	})
+
+	t.Run("Invalid files are rejected on start", func(t *testing.T) {
+		_, err := tlscredentials.NewServerCredentials(tlscredentials.ServerConfig{
+			CertFile: file("server.pem"),
+			KeyFile:  file("client-key.pem"),
+		}, logger)
+		require.Error(t, err)
+	})
+}
+
+func serve(t *testing.T, creds credentials.TransportCredentials) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.Creds(creds))
+	healthpb.RegisterHealthServer(server, grpchealth.NewServer())
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func check(t *testing.T, address string, creds credentials.TransportCredentials) error {
+	t.Helper()
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+type certificateAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newCA(t *testing.T) certificateAuthority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificateAuthority{cert: cert, key: key}
+}
+
+func (ca certificateAuthority) writeCert(t *testing.T, path string) {
+	t.Helper()
+	writePEM(t, path, "CERTIFICATE", ca.cert.Raw)
+}
+
+// issue writes certificate valid for both server and client authentication
+func (ca certificateAuthority) issue(t *testing.T, name, certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+}
+
+// writePEM moves modification time forward, so rewritten file is detected even on coarse grained file systems
+func writePEM(t *testing.T, path, blockType string, data []byte) {
+	t.Helper()
+	modTime := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	}
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
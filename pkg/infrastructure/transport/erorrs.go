@@ -4,7 +4,14 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
 )
 
 type errorSet map[error]struct{}
@@ -24,14 +31,43 @@ func (s errorSet) Has(err error) bool {
 
 var badRequestErrorCodes = newErrorSet()
 
-var notFoundErrorCodes = newErrorSet()
+var notFoundErrorCodes = newErrorSet(
+	model.ErrOrderNotFound,
+)
+
+var unauthorizedErrorCodes = newErrorSet(
+	service.ErrUnauthenticated,
+)
 
-var unauthorizedErrorCodes = newErrorSet()
+var permissionDeniedErrorCodes = newErrorSet(
+	service.ErrPermissionDenied,
+)
 
-var permissionDeniedErrorCodes = newErrorSet()
+var resourceExhaustedErrorCodes = newErrorSet(
+	service.ErrQuotaExceeded,
+)
+
+var abortedErrorCodes = newErrorSet(
+	service.ErrWatchLagged,
+)
 
 var internalErrorCodes = newErrorSet()
 
+// NewGRPCErrorMiddleware translates application errors into GRPC status codes
+func NewGRPCErrorMiddleware() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, ErrorInterceptor{}.TranslateGRPCError(err)
+	}
+}
+
+// NewGRPCErrorStreamMiddleware translates application errors of streaming methods into GRPC status codes
+func NewGRPCErrorStreamMiddleware() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return ErrorInterceptor{}.TranslateGRPCError(handler(srv, stream))
+	}
+}
+
 // getGRPCCode recursively unwraps joined errors and returns GRPC code by the first meaningful error
 func getGRPCCode(err error) codes.Code {
 	cause := errors.Cause(err)
@@ -47,6 +83,10 @@ func getGRPCCode(err error) codes.Code {
 		return codes.Unauthenticated
 	case isPermissionDeniedError(cause):
 		return codes.PermissionDenied
+	case isResourceExhaustedError(cause):
+		return codes.ResourceExhausted
+	case isAbortedError(cause):
+		return codes.Aborted
 	case isInternalError(cause):
 		return codes.Internal
 	}
@@ -61,6 +101,21 @@ func getGRPCCode(err error) codes.Code {
 	}
 }
 
+// withRetryInfo tells client when exceeded quota allows to retry
+func withRetryInfo(st *status.Status, err error) *status.Status {
+	var quotaErr *service.QuotaExceededError
+	if !errors.As(err, &quotaErr) || quotaErr.RetryAfter <= 0 {
+		return st
+	}
+	detailed, detailsErr := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(quotaErr.RetryAfter),
+	})
+	if detailsErr != nil {
+		return st
+	}
+	return detailed
+}
+
 func isWarnLevel(err error) bool {
 	switch getGRPCCode(err) {
 	case codes.Canceled,
@@ -69,6 +124,8 @@ func isWarnLevel(err error) bool {
 		codes.InvalidArgument,
 		codes.NotFound,
 		codes.FailedPrecondition,
+		codes.ResourceExhausted,
+		codes.Aborted,
 		codes.Unauthenticated:
 		return true
 	default:
@@ -92,6 +149,14 @@ func isPermissionDeniedError(cause error) bool {
 	return permissionDeniedErrorCodes.Has(cause)
 }
 
+func isResourceExhaustedError(cause error) bool {
+	return resourceExhaustedErrorCodes.Has(cause)
+}
+
+func isAbortedError(cause error) bool {
+	return abortedErrorCodes.Has(cause)
+}
+
 func isInternalError(cause error) bool {
 	return internalErrorCodes.Has(cause)
 }
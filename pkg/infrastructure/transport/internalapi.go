@@ -2,34 +2,43 @@ package transport
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 
 	"order/api/server/orderinternal"
 	appmodel "order/pkg/application/model"
 	"order/pkg/application/service"
-	"order/pkg/infrastructure/mysql/query"
+	"order/pkg/domain/model"
 )
 
 func NewOrderInternalAPI(
-	orderQueryService query.OrderQueryService,
+	orderQueryService service.OrderQueryService,
 	orderService service.OrderService,
+	orderWatchService service.OrderWatchService,
+	watchHeartbeatInterval time.Duration,
 ) orderinternal.OrderInternalServiceServer {
 	return &orderInternalAPI{
-		orderQueryService: orderQueryService,
-		orderService:      orderService,
+		orderQueryService:      orderQueryService,
+		orderService:           orderService,
+		orderWatchService:      orderWatchService,
+		watchHeartbeatInterval: watchHeartbeatInterval,
 	}
 }
 
 type orderInternalAPI struct {
-	orderQueryService query.OrderQueryService
-	orderService      service.OrderService
+	orderQueryService      service.OrderQueryService
+	orderService           service.OrderService
+	orderWatchService      service.OrderWatchService
+	watchHeartbeatInterval time.Duration
 
 	orderinternal.UnimplementedOrderInternalServiceServer
 }
 
 func (a *orderInternalAPI) CreateOrder(ctx context.Context, request *orderinternal.CreateOrderRequest) (*orderinternal.CreateOrderResponse, error) {
-	userID, err := uuid.Parse(request.UserID)
+	userID, err := requestUserID(ctx, request.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -69,31 +78,42 @@ func (a *orderInternalAPI) GetOrder(ctx context.Context, request *orderinternal.
 	if err != nil {
 		return nil, err
 	}
-	if order == nil {
-		return &orderinternal.GetOrderResponse{}, nil
+
+	return &orderinternal.GetOrderResponse{
+		Order: toOrder(order),
+	}, nil
+}
+
+func (a *orderInternalAPI) GetOrderHistory(ctx context.Context, request *orderinternal.GetOrderHistoryRequest) (*orderinternal.GetOrderHistoryResponse, error) {
+	orderID, err := uuid.Parse(request.OrderID)
+	if err != nil {
+		return nil, err
 	}
 
-	var items []*orderinternal.OrderItem
-	for _, item := range order.Items {
-		items = append(items, &orderinternal.OrderItem{
-			ProductID: item.ProductID.String(),
-			Quantity:  int32(item.Quantity),
+	events, err := a.orderQueryService.GetOrderHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*orderinternal.OrderEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, &orderinternal.OrderEvent{
+			EventID:    event.EventID,
+			Type:       event.Type,
+			Payload:    event.Payload,
+			Actor:      event.Actor,
+			Source:     event.Source,
+			OccurredAt: event.OccurredAt.UnixMilli(),
 		})
 	}
 
-	return &orderinternal.GetOrderResponse{
-		Order: &orderinternal.Order{
-			OrderID:    order.OrderID.String(),
-			UserID:     order.UserID.String(),
-			Status:     order.Status,
-			Items:      items,
-			TotalPrice: order.TotalPrice,
-		},
+	return &orderinternal.GetOrderHistoryResponse{
+		Events: result,
 	}, nil
 }
 
 func (a *orderInternalAPI) CreateOrderAsync(ctx context.Context, request *orderinternal.CreateOrderRequest) (*orderinternal.CreateOrderResponse, error) {
-	userID, err := uuid.Parse(request.UserID)
+	userID, err := requestUserID(ctx, request.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -122,3 +142,121 @@ func (a *orderInternalAPI) CreateOrderAsync(ctx context.Context, request *orderi
 		OrderID: orderID.String(),
 	}, nil
 }
+
+// WatchOrder streams order snapshot or events missed since request.LastEventID followed by new events.
+// Heartbeats keep idle stream alive through proxies, stream ends after order is deleted
+func (a *orderInternalAPI) WatchOrder(request *orderinternal.WatchOrderRequest, stream orderinternal.OrderInternalService_WatchOrderServer) error {
+	ctx := stream.Context()
+	orderID, err := uuid.Parse(request.OrderID)
+	if err != nil {
+		return err
+	}
+
+	watch, err := a.orderWatchService.WatchOrder(ctx, orderID, request.LastEventID)
+	if err != nil {
+		return err
+	}
+	defer watch.Close()
+
+	if watch.Snapshot != nil {
+		err = stream.Send(&orderinternal.WatchOrderResponse{
+			LastEventID: watch.LastEventID,
+			Update:      &orderinternal.WatchOrderResponse_Snapshot{Snapshot: toOrder(*watch.Snapshot)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	deleted, err := sendOrderEvents(stream, watch.Missed)
+	if err != nil || deleted {
+		return err
+	}
+
+	heartbeat := time.NewTicker(a.watchHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case record, ok := <-watch.Events():
+			if !ok {
+				return errors.WithStack(service.ErrWatchLagged)
+			}
+			records, err := watch.CatchUp(ctx, record)
+			if err != nil {
+				return err
+			}
+			deleted, err = sendOrderEvents(stream, records)
+			if err != nil || deleted {
+				return err
+			}
+		case now := <-heartbeat.C:
+			err = stream.Send(&orderinternal.WatchOrderResponse{
+				LastEventID: watch.LastEventID,
+				Update:      &orderinternal.WatchOrderResponse_Heartbeat{Heartbeat: &orderinternal.Heartbeat{SentAt: now.UnixMilli()}},
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendOrderEvents reports whether order is deleted, stream ends then
+func sendOrderEvents(stream orderinternal.OrderInternalService_WatchOrderServer, records []model.OrderEventRecord) (bool, error) {
+	for _, record := range records {
+		if err := sendOrderEvent(stream, record); err != nil {
+			return false, err
+		}
+		if _, deleted := record.Event.(model.OrderDeleted); deleted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func sendOrderEvent(stream orderinternal.OrderInternalService_WatchOrderServer, record model.OrderEventRecord) error {
+	payload, err := json.Marshal(record.Event)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return stream.Send(&orderinternal.WatchOrderResponse{
+		LastEventID: record.ID,
+		Update: &orderinternal.WatchOrderResponse_Event{Event: &orderinternal.OrderEvent{
+			EventID:    record.ID,
+			Type:       record.Event.Type(),
+			Payload:    string(payload),
+			Actor:      record.Actor,
+			Source:     record.Source,
+			OccurredAt: record.OccurredAt.UnixMilli(),
+		}},
+	})
+}
+
+// toOrder is shared by GetOrder and WatchOrder snapshot, so both return order the same way
+func toOrder(order appmodel.OrderDetails) *orderinternal.Order {
+	var items []*orderinternal.OrderItem
+	for _, item := range order.Items {
+		items = append(items, &orderinternal.OrderItem{
+			ProductID: item.ProductID.String(),
+			Quantity:  int32(item.Quantity),
+		})
+	}
+	return &orderinternal.Order{
+		OrderID:    order.OrderID.String(),
+		UserID:     order.UserID.String(),
+		Status:     order.Status,
+		Items:      items,
+		TotalPrice: order.TotalPrice,
+	}
+}
+
+// requestUserID defaults to authenticated user when user ID is omitted,
+// whether caller may act on behalf of given user is checked by order service
+func requestUserID(ctx context.Context, requestUserID string) (uuid.UUID, error) {
+	identity, ok := service.IdentityFromContext(ctx)
+	if ok && requestUserID == "" {
+		return identity.UserID, nil
+	}
+	return uuid.Parse(requestUserID)
+}
@@ -22,7 +22,7 @@ func (i ErrorInterceptor) TranslateGRPCError(err error) error {
 		return err
 	}
 
-	return status.Error(getGRPCCode(err), err.Error())
+	return withRetryInfo(status.New(getGRPCCode(err), err.Error()), err).Err()
 }
 
 func MakeLoggerServerInterceptor(logger *log.Logger) grpc.UnaryServerInterceptor {
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order/pkg/application/service"
+	"order/pkg/infrastructure/auth"
+)
+
+const (
+	authorizationMetadataKey = "authorization"
+	bearerPrefix             = "bearer "
+)
+
+// NewGRPCAuthMiddleware requires bearer JWT for every method except ones starting with public prefixes,
+// like health checks called by infrastructure without tokens
+func NewGRPCAuthMiddleware(verifier *auth.Verifier, publicPrefixes ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		for _, prefix := range publicPrefixes {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(ctx, req)
+			}
+		}
+
+		ctx, err = authenticate(ctx, verifier)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewGRPCAuthStreamMiddleware is NewGRPCAuthMiddleware for streaming methods
+func NewGRPCAuthStreamMiddleware(verifier *auth.Verifier, publicPrefixes ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		for _, prefix := range publicPrefixes {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(srv, stream)
+			}
+		}
+
+		ctx, err := authenticate(stream.Context(), verifier)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, verifier *auth.Verifier) (context.Context, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := verifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return service.WithIdentity(ctx, identity), nil
+}
+
+// contextServerStream replaces context of stream, grpc.ServerStream has no other way to pass values to handler
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationMetadataKey)
+	if len(values) == 0 {
+		return "", errors.Wrap(service.ErrUnauthenticated, "authorization metadata is missing")
+	}
+	value := values[0]
+	if len(value) <= len(bearerPrefix) || !strings.EqualFold(value[:len(bearerPrefix)], bearerPrefix) {
+		return "", errors.Wrap(service.ErrUnauthenticated, "authorization is not bearer token")
+	}
+	return value[len(bearerPrefix):], nil
+}
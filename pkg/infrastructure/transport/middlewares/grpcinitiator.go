@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order/pkg/application/service"
+)
+
+const (
+	actorMetadataKey = "x-actor"
+	// unverifiedActorPrefix marks actor claimed by caller, so it can't be taken for authenticated user in history
+	unverifiedActorPrefix = "unverified:"
+)
+
+// NewGRPCInitiatorMiddleware puts called method and actor into context for order history,
+// actor is authenticated user or taken from request metadata as unverified if authentication is disabled
+func NewGRPCInitiatorMiddleware() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		initiator := service.Initiator{
+			Source: info.FullMethod,
+		}
+		if identity, ok := service.IdentityFromContext(ctx); ok {
+			initiator.Actor = "user:" + identity.Subject
+		} else if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if actors := md.Get(actorMetadataKey); len(actors) > 0 {
+				initiator.Actor = unverifiedActorPrefix + actors[0]
+			}
+		}
+		return handler(service.WithInitiator(ctx, initiator), req)
+	}
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"order/pkg/infrastructure/metrics"
+)
+
+func NewGRPCMetricsMiddleware(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+
+		resp, err = handler(ctx, req)
+
+		code := status.Code(err).String()
+		m.GRPCRequests.WithLabelValues(info.FullMethod, code).Inc()
+		m.GRPCRequestDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+// NewGRPCClientMetricsMiddleware measures calls to service with given name
+func NewGRPCClientMetricsMiddleware(m *metrics.Metrics, service string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		m.OutboundDuration.WithLabelValues(service, method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
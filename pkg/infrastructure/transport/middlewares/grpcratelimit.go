@@ -0,0 +1,200 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"order/pkg/application/service"
+)
+
+// RateLimit is token bucket refilled with RPS tokens per second up to Burst tokens
+type RateLimit struct {
+	RPS   float64
+	Burst int
+}
+
+type RateLimitConfig struct {
+	Default RateLimit
+	Methods map[string]RateLimit
+	// ByAddress limits requests without authenticated user by client address, such callers are unknown otherwise.
+	// All clients behind the same address, like services calling through one proxy, share limit
+	ByAddress bool
+	// TrustedProxies are networks of proxies, like REST gateway, whose client address is the last x-forwarded-for address.
+	// Request of trusted proxy without the header is limited by proxy address
+	TrustedProxies []*net.IPNet
+	// LimitUnknownCallers makes all unknown callers share one limit, they aren't limited otherwise
+	LimitUnknownCallers bool
+	// SkipPrefixes are prefixes of methods which aren't limited
+	SkipPrefixes []string
+}
+
+// idleLimiterTTL is how long limiter of caller without requests is kept, full bucket is the same as new one
+const idleLimiterTTL = 10 * time.Minute
+
+// forwardedForMetadataKey is set by REST gateway, the last address is its client and the rest are taken from request headers
+const forwardedForMetadataKey = "x-forwarded-for"
+
+// unknownCaller is key of limit shared by all unknown callers
+const unknownCaller = "unknown"
+
+// NewGRPCRateLimitMiddleware limits requests of every caller to every method with method limit or default limit
+func NewGRPCRateLimitMiddleware(config RateLimitConfig) grpc.UnaryServerInterceptor {
+	limiters := newRateLimiters(config)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if err = limiters.allow(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewGRPCRateLimitStreamMiddleware is NewGRPCRateLimitMiddleware for streaming methods, only opening of stream is limited
+func NewGRPCRateLimitStreamMiddleware(config RateLimitConfig) grpc.StreamServerInterceptor {
+	limiters := newRateLimiters(config)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := limiters.allow(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// caller is authenticated user, or client address if limiting by address is enabled, it is empty when caller is unknown
+func (l *rateLimiters) caller(ctx context.Context) string {
+	if identity, ok := service.IdentityFromContext(ctx); ok {
+		return "user:" + identity.UserID.String()
+	}
+	if !l.config.ByAddress {
+		return ""
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	// port differs between connections of the same caller
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	if !l.trustedProxy(host) {
+		return "peer:" + host
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	forwardedFor := md.Get(forwardedForMetadataKey)
+	if len(forwardedFor) == 0 {
+		return "peer:" + host
+	}
+	addresses := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+	if client := strings.TrimSpace(addresses[len(addresses)-1]); client != "" {
+		return "peer:" + client
+	}
+	return "peer:" + host
+}
+
+func (l *rateLimiters) trustedProxy(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range l.config.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type rateLimiterKey struct {
+	caller string
+	method string
+}
+
+type rateLimiter struct {
+	*rate.Limiter
+	lastUsed time.Time
+}
+
+type rateLimiters struct {
+	config RateLimitConfig
+
+	mu        sync.Mutex
+	limiters  map[rateLimiterKey]*rateLimiter
+	lastSweep time.Time
+}
+
+func newRateLimiters(config RateLimitConfig) *rateLimiters {
+	return &rateLimiters{
+		config:    config,
+		limiters:  make(map[rateLimiterKey]*rateLimiter),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *rateLimiters) allow(ctx context.Context, method string) error {
+	for _, prefix := range l.config.SkipPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return nil
+		}
+	}
+
+	key := rateLimiterKey{caller: l.caller(ctx), method: method}
+	if key.caller == "" {
+		if !l.config.LimitUnknownCallers {
+			return nil
+		}
+		key.caller = unknownCaller
+	}
+
+	limit, ok := l.config.Methods[method]
+	if !ok {
+		limit = l.config.Default
+	}
+	limiter := l.get(key, limit)
+
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		// burst is zero, method is not allowed at all
+		return &service.QuotaExceededError{Quota: "rate limit of " + method}
+	}
+	if delay := reservation.Delay(); delay > 0 {
+		// tokens are returned, so rejected requests don't postpone next allowed one
+		reservation.Cancel()
+		return &service.QuotaExceededError{
+			Quota:      "rate limit of " + method,
+			RetryAfter: delay,
+		}
+	}
+	return nil
+}
+
+func (l *rateLimiters) get(key rateLimiterKey, limit RateLimit) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > idleLimiterTTL {
+		for k, limiter := range l.limiters {
+			if now.Sub(limiter.lastUsed) > idleLimiterTTL {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &rateLimiter{Limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)}
+		l.limiters[key] = limiter
+	}
+	limiter.lastUsed = now
+	return limiter.Limiter
+}
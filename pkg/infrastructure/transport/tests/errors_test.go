@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	"order/pkg/infrastructure/transport"
+)
+
+func TestGRPCErrorMiddleware(t *testing.T) {
+	middleware := transport.NewGRPCErrorMiddleware()
+	call := func(err error) error {
+		_, err = middleware(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+			return nil, err
+		})
+		return err
+	}
+
+	require.NoError(t, call(nil))
+	require.Equal(t, codes.Unauthenticated, status.Code(call(errors.Wrap(service.ErrUnauthenticated, "token expired"))))
+	require.Equal(t, codes.PermissionDenied, status.Code(call(service.ErrPermissionDenied)))
+	require.Equal(t, codes.NotFound, status.Code(call(errors.WithStack(model.ErrOrderNotFound))))
+	require.Equal(t, codes.ResourceExhausted, status.Code(call(errors.WithStack(&service.QuotaExceededError{Quota: "open orders"}))))
+	require.Equal(t, codes.Unknown, status.Code(call(errors.New("unexpected"))))
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order/pkg/application/service"
+	"order/pkg/infrastructure/transport/middlewares"
+)
+
+func TestGRPCInitiatorMiddleware(t *testing.T) {
+	middleware := middlewares.NewGRPCInitiatorMiddleware()
+	info := &grpc.UnaryServerInfo{FullMethod: "/order.OrderInternalService/CreateOrder"}
+	call := func(ctx context.Context) service.Initiator {
+		var initiator service.Initiator
+		_, err := middleware(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			initiator = service.InitiatorFromContext(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return initiator
+	}
+
+	t.Run("Actor from metadata is marked unverified", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-actor", "user:admin"))
+
+		initiator := call(ctx)
+		require.Equal(t, "unverified:user:admin", initiator.Actor)
+		require.Equal(t, info.FullMethod, initiator.Source)
+	})
+
+	t.Run("Authenticated user is actor", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-actor", "user:admin"))
+		ctx = service.WithIdentity(ctx, service.Identity{UserID: uuid.New(), Subject: "customer"})
+
+		require.Equal(t, "user:customer", call(ctx).Actor)
+	})
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"order/pkg/application/service"
+	"order/pkg/infrastructure/transport"
+	"order/pkg/infrastructure/transport/middlewares"
+)
+
+func TestGRPCRateLimitMiddleware(t *testing.T) {
+	const createOrder = "/Order.OrderInternalService/CreateOrder"
+	config := middlewares.RateLimitConfig{
+		Default:      middlewares.RateLimit{RPS: 100, Burst: 100},
+		Methods:      map[string]middlewares.RateLimit{createOrder: {RPS: 1, Burst: 2}},
+		SkipPrefixes: []string{"/grpc.health.v1.Health/"},
+	}
+	_, loopback, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+	byAddress := config
+	byAddress.ByAddress = true
+	byAddress.TrustedProxies = []*net.IPNet{loopback}
+	unknownLimited := config
+	unknownLimited.LimitUnknownCallers = true
+
+	errorMiddleware := transport.NewGRPCErrorMiddleware()
+	newCall := func(config middlewares.RateLimitConfig) func(ctx context.Context, method string) error {
+		rateLimit := middlewares.NewGRPCRateLimitMiddleware(config)
+		return func(ctx context.Context, method string) error {
+			info := &grpc.UnaryServerInfo{FullMethod: method}
+			_, err := errorMiddleware(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return rateLimit(ctx, req, info, func(context.Context, interface{}) (interface{}, error) {
+					return struct{}{}, nil
+				})
+			})
+			return err
+		}
+	}
+	call := newCall(config)
+	callByAddress := newCall(byAddress)
+	fromPeer := func(ip net.IP) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: ip, Port: 40000}})
+	}
+	withUser := func() context.Context {
+		return service.WithIdentity(context.Background(), service.Identity{UserID: uuid.New()})
+	}
+
+	t.Run("Requests over burst are rejected with retry delay", func(t *testing.T) {
+		ctx := withUser()
+		require.NoError(t, call(ctx, createOrder))
+		require.NoError(t, call(ctx, createOrder))
+
+		err := call(ctx, createOrder)
+		st := status.Convert(err)
+		require.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Len(t, st.Details(), 1)
+		retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+		require.True(t, ok)
+		require.InDelta(t, time.Second, retryInfo.RetryDelay.AsDuration(), float64(100*time.Millisecond))
+	})
+
+	t.Run("Callers and methods have separate limits", func(t *testing.T) {
+		ctx := withUser()
+		require.NoError(t, call(ctx, createOrder))
+		require.NoError(t, call(ctx, createOrder))
+		require.Error(t, call(ctx, createOrder))
+
+		require.NoError(t, call(ctx, "/Order.OrderInternalService/GetOrder"))
+		require.NoError(t, call(withUser(), createOrder))
+	})
+
+	t.Run("Clients of REST gateway have separate limits", func(t *testing.T) {
+		fromClient := func(forwardedFor string) context.Context {
+			return metadata.NewIncomingContext(fromPeer(net.IPv4(127, 0, 0, 1)), metadata.Pairs("x-forwarded-for", forwardedFor))
+		}
+
+		// the first address comes from client header and is ignored
+		require.NoError(t, callByAddress(fromClient("10.0.0.1, 192.0.2.1"), createOrder))
+		require.NoError(t, callByAddress(fromClient("10.0.0.2, 192.0.2.1"), createOrder))
+		require.Error(t, callByAddress(fromClient("192.0.2.1"), createOrder))
+		require.NoError(t, callByAddress(fromClient("192.0.2.2"), createOrder))
+	})
+
+	t.Run("Trusted proxy without forwarded address is limited by its own address", func(t *testing.T) {
+		sidecar := fromPeer(net.IPv4(127, 0, 0, 2))
+		require.NoError(t, callByAddress(sidecar, createOrder))
+		require.NoError(t, callByAddress(sidecar, createOrder))
+		require.Error(t, callByAddress(sidecar, createOrder))
+	})
+
+	t.Run("Forwarded address of untrusted peer is ignored", func(t *testing.T) {
+		ctx := func(forwardedFor string) context.Context {
+			return metadata.NewIncomingContext(fromPeer(net.IPv4(192, 0, 2, 10)), metadata.Pairs("x-forwarded-for", forwardedFor))
+		}
+		require.NoError(t, callByAddress(ctx("192.0.2.11"), createOrder))
+		require.NoError(t, callByAddress(ctx("192.0.2.12"), createOrder))
+		require.Error(t, callByAddress(ctx("192.0.2.13"), createOrder))
+	})
+
+	t.Run("Callers without user are unknown unless limited by address", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			require.NoError(t, call(fromPeer(net.IPv4(192, 0, 2, 20)), createOrder))
+			require.NoError(t, call(context.Background(), createOrder))
+		}
+	})
+
+	t.Run("Unknown callers share one limit when they are limited", func(t *testing.T) {
+		callUnknownLimited := newCall(unknownLimited)
+		require.NoError(t, callUnknownLimited(fromPeer(net.IPv4(192, 0, 2, 30)), createOrder))
+		require.NoError(t, callUnknownLimited(context.Background(), createOrder))
+		require.Error(t, callUnknownLimited(fromPeer(net.IPv4(192, 0, 2, 31)), createOrder))
+		// authenticated users keep their own limits
+		require.NoError(t, callUnknownLimited(withUser(), createOrder))
+	})
+
+	t.Run("Skipped methods are not limited", func(t *testing.T) {
+		ctx := withUser()
+		for i := 0; i < 5; i++ {
+			require.NoError(t, call(ctx, "/grpc.health.v1.Health/Check"))
+		}
+		// health checks didn't use tokens of limited method
+		require.NoError(t, call(ctx, createOrder))
+	})
+}